@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -279,25 +280,32 @@ func (c *AuditCollector) processEvent(ev AuditEvent) {
 
 	// Track write bytes
 	if op == "WRITE" && ev.After.Size != "" {
-		var sz int64
-		fmt.Sscanf(ev.After.Size, "%d", &sz)
-		c.WriteBytesTotal.WithLabelValues(auth, ev.ClientIP).Add(float64(sz))
+		sz, err := strconv.ParseInt(ev.After.Size, 10, 64)
+		if err != nil {
+			log.Printf("audit: invalid size of %s event %s: %v", op, ev.UUID, err)
+		} else {
+			c.WriteBytesTotal.WithLabelValues(auth, ev.ClientIP).Add(float64(sz))
+		}
 	}
 
 	// Track file lifecycle
 	if ev.UUID != "" {
+		ts, err := strconv.ParseInt(ev.Timestamp, 10, 64)
+		if err != nil && (op == "CREATE" || op == "DELETE") {
+			log.Printf("audit: invalid timestamp of %s event %s: %v", op, ev.UUID, err)
+		}
 		c.state.mu.Lock()
 		switch op {
 		case "CREATE":
-			var ts int64
-			fmt.Sscanf(ev.Timestamp, "%d", &ts)
-			c.state.openFiles[ev.UUID] = ts
+			if err == nil {
+				c.state.openFiles[ev.UUID] = ts
+			}
 		case "DELETE":
 			if start, ok := c.state.openFiles[ev.UUID]; ok {
-				var ts int64
-				fmt.Sscanf(ev.Timestamp, "%d", &ts)
-				duration := ts - start
-				c.LifecycleSeconds.WithLabelValues(auth, account).Add(float64(duration))
+				if err == nil {
+					duration := ts - start
+					c.LifecycleSeconds.WithLabelValues(auth, account).Add(float64(duration))
+				}
 				delete(c.state.openFiles, ev.UUID)
 			}
 		}
@@ -320,16 +328,21 @@ func (c *AuditCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel
-func (c *AuditCollector) Collect(ch chan<- prometheus.Metric) {
-	// The actual collection happens in the background watcher
-	// This just reports the current state of the counters
+// Update sends the current state of the counters; it never fails since the
+// actual collection happens in the background watcher
+func (c *AuditCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for _, metric := range c.collectorList() {
 		metric.Collect(ch)
 	}
+	return nil
+}
+
+// Collect sends all the collected metrics to the provided prometheus channel
+func (c *AuditCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectWithContext(context.Background(), ch)
 }
 
-// CollectWithContext allows for context-aware collection (optional interface)
+// CollectWithContext is Collect; the counters are served from memory so ctx is unused
 func (c *AuditCollector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.Collect(ch)
+	c.Update(ctx, ch)
 }
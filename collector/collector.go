@@ -1,8 +1,31 @@
 package collector
 
+import (
+	"context"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ContextCollector is a prometheus.Collector whose collection can be bound to a
+// context, e.g. the one of the scrape: canceling it kills the running eos commands.
+type ContextCollector interface {
+	prometheus.Collector
+	CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric)
+}
+
+// Collector is implemented by all the EOS collectors. Update runs the underlying
+// eos commands with ctx and sends the resulting metrics, or returns an error without
+// sending anything when they could not be collected. Collect and CollectWithContext
+// do the same but only log the error.
+type Collector interface {
+	ContextCollector
+	Update(ctx context.Context, ch chan<- prometheus.Metric) error
+}
+
 type CollectorOpts struct {
 	Cluster           string
-	Timeout           int
-	AuditLogPath      string // Path to the audit log symlink (default: /var/log/eos/mgm/audit/audit.zstd)
-	AuditPollInterval int    // Interval in seconds to check for new audit log files (default: 30)
+	Client            *eosclient.Client // Long-lived client of the instance, shared by the collectors
+	AuditLogPath      string            // Path to the audit log symlink (default: /var/log/eos/mgm/audit/audit.zstd)
+	AuditPollInterval int               // Interval in seconds to check for new audit log files (default: 30)
 }
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// outputsExecutor answers the eos commands with the output set for their
+// command line, an empty one by default
+type outputsExecutor struct {
+	mu      sync.Mutex
+	outputs map[string]string
+}
+
+func (e *outputsExecutor) set(args, output string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.outputs[args] = output
+}
+
+func (e *outputsExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.outputs[strings.Join(args, " ")], "", nil
+}
+
+func newTestOpts(t *testing.T, executor eosclient.Executor) *CollectorOpts {
+	t.Helper()
+	client, err := eosclient.New(&eosclient.Options{Executor: executor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CollectorOpts{Cluster: "test", Client: client}
+}
+
+// gather collects c through a registry, which checks the metrics against the
+// descriptors, and returns the families by name
+func gather(t *testing.T, c prometheus.Collector) map[string]*dto.MetricFamily {
+	t.Helper()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+	return byName
+}
+
+func TestCollectorsExportCounters(t *testing.T) {
+	executor := &outputsExecutor{outputs: map[string]string{
+		"io stat -m": `measurement=bytes_read total=1099511627776 60s=1 300s=2 3600s=3 86400s=4
+measurement=read_calls total=7000 60s=1 300s=2 3600s=3 86400s=4
+`,
+		"ns stat -m": `uid=all gid=all ns.cache.files.requests=100
+uid=all gid=all ns.cache.files.hits=90
+uid=all gid=all ns.total.files=12
+uid=all gid=all cmd=Access total=1200 5s=0.00 60s=1.00 300s=0.50 3600s=0.10 exec=0.01 execsig=0.00 exec99=0.02 execmax=0.03
+`,
+	}}
+	opts := newTestOpts(t, executor)
+
+	// The sums EOS accumulates since the MGM started are counters, the other
+	// metrics gauges, e.g. eos_ns_files_total
+	for _, tc := range []struct {
+		collector prometheus.Collector
+		counters  *regexp.Regexp
+		want      []string
+	}{
+		{NewIOInfoCollector(opts), regexp.MustCompile(`^eos_io_.*_total$`), []string{"eos_io_bytes_read_total", "eos_io_read_calls_total"}},
+		{NewNSCollector(opts), regexp.MustCompile(`^eos_ns_cache_.*_(requests|hits)_total$`), []string{"eos_ns_cache_files_requests_total", "eos_ns_cache_files_hits_total", "eos_ns_files_total"}},
+		{NewNSActivityCollector(opts), regexp.MustCompile(`^eos_ns_stat_sum_total$`), []string{"eos_ns_stat_sum_total"}},
+	} {
+		families := gather(t, tc.collector)
+		for _, name := range tc.want {
+			if families[name] == nil {
+				t.Errorf("%s is missing", name)
+			}
+		}
+		for name, family := range families {
+			counter := family.GetType() == dto.MetricType_COUNTER
+			if counter != tc.counters.MatchString(name) {
+				t.Errorf("%s is a %s", name, family.GetType())
+			}
+		}
+	}
+}
+
+func TestRemovedFSDisappears(t *testing.T) {
+	executor := &outputsExecutor{outputs: map[string]string{
+		"-r 0 0 fs ls -m": `host=fst-1.cern.ch port=1095 id=12 path=/data01 stat.boot=booted configstatus=rw stat.geotag=site::rack1 stat.statfs.usedbytes=100
+host=fst-2.cern.ch port=1095 id=13 path=/data01 stat.boot=booted configstatus=rw stat.geotag=site::rack2 stat.statfs.usedbytes=200
+`,
+	}}
+	fs := NewFSCollector(newTestOpts(t, executor))
+
+	ids := func() map[string]bool {
+		ids := make(map[string]bool)
+		for _, m := range gather(t, fs)["eos_fs_statfs_usedbytes"].GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "fs" {
+					ids[label.GetValue()] = true
+				}
+			}
+		}
+		return ids
+	}
+	if got := ids(); !got["12"] || !got["13"] {
+		t.Fatalf("expected the used bytes of filesystems 12 and 13, got %v", got)
+	}
+
+	executor.set("-r 0 0 fs ls -m", "host=fst-1.cern.ch port=1095 id=12 path=/data01 stat.boot=booted configstatus=rw stat.geotag=site::rack1 stat.statfs.usedbytes=100\n")
+	if got := ids(); !got["12"] || got["13"] {
+		t.Fatalf("expected the used bytes of filesystem 12 only, got %v", got)
+	}
+}
@@ -1,13 +1,7 @@
 package collector
 
 import (
-	"bufio"
 	"context"
-	"fmt"
-	"log"
-	"os"
-	"strconv"
-	"strings"
 
 	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,51 +9,52 @@ import (
 
 type FSCollector struct {
 	*CollectorOpts
-	Host                       *prometheus.GaugeVec
-	Port                       *prometheus.GaugeVec
-	Id                         *prometheus.GaugeVec
-	Uuid                       *prometheus.GaugeVec
-	Path                       *prometheus.GaugeVec
-	Schedgroup                 *prometheus.GaugeVec
-	StatBoot                   *prometheus.GaugeVec
-	Configstatus               *prometheus.GaugeVec
-	Headroom                   *prometheus.GaugeVec
-	StatErrc                   *prometheus.GaugeVec
-	StatErrmsg                 *prometheus.GaugeVec
-	StatDiskLoad               *prometheus.GaugeVec
-	StatDiskReadratemb         *prometheus.GaugeVec
-	StatDiskWriteratemb        *prometheus.GaugeVec
-	StatNetEthratemib          *prometheus.GaugeVec
-	StatNetInratemib           *prometheus.GaugeVec
-	StatNetOutratemib          *prometheus.GaugeVec
-	StatRopen                  *prometheus.GaugeVec
-	StatWopen                  *prometheus.GaugeVec
-	StatStatfsFreebytes        *prometheus.GaugeVec
-	StatStatfsUsedbytes        *prometheus.GaugeVec
-	StatStatfsCapacity         *prometheus.GaugeVec
-	StatUsedfiles              *prometheus.GaugeVec
-	StatStatfsFfree            *prometheus.GaugeVec
-	StatStatfsFused            *prometheus.GaugeVec
-	StatStatfsFiles            *prometheus.GaugeVec
-	Drainstatus                *prometheus.GaugeVec
-	StatDrainprogress          *prometheus.GaugeVec
-	StatDrainfiles             *prometheus.GaugeVec
-	StatDrainbytesleft         *prometheus.GaugeVec
-	StatDrainretry             *prometheus.GaugeVec
-	StatDrainFailed            *prometheus.GaugeVec
-	Graceperiod                *prometheus.GaugeVec
-	StatTimeleft               *prometheus.GaugeVec
-	StatActive                 *prometheus.GaugeVec
-	StatBalancerRunning        *prometheus.GaugeVec
-	StatDrainerRunning         *prometheus.GaugeVec
-	StatDiskIops               *prometheus.GaugeVec
-	StatDiskBw                 *prometheus.GaugeVec
-	StatGeotag                 *prometheus.GaugeVec
-	StatHealth                 *prometheus.GaugeVec
-	StatHealthRedundancyFactor *prometheus.GaugeVec
-	StatHealthDrivesFailed     *prometheus.GaugeVec
-	StatHealthDrivesTotal      *prometheus.GaugeVec
-	StatHealthIndicator        *prometheus.GaugeVec
+	batchCollector
+	Host                       *metricVec
+	Port                       *metricVec
+	Id                         *metricVec
+	Uuid                       *metricVec
+	Path                       *metricVec
+	Schedgroup                 *metricVec
+	StatBoot                   *metricVec
+	Configstatus               *metricVec
+	Headroom                   *metricVec
+	StatErrc                   *metricVec
+	StatErrmsg                 *metricVec
+	StatDiskLoad               *metricVec
+	StatDiskReadratemb         *metricVec
+	StatDiskWriteratemb        *metricVec
+	StatNetEthratemib          *metricVec
+	StatNetInratemib           *metricVec
+	StatNetOutratemib          *metricVec
+	StatRopen                  *metricVec
+	StatWopen                  *metricVec
+	StatStatfsFreebytes        *metricVec
+	StatStatfsUsedbytes        *metricVec
+	StatStatfsCapacity         *metricVec
+	StatUsedfiles              *metricVec
+	StatStatfsFfree            *metricVec
+	StatStatfsFused            *metricVec
+	StatStatfsFiles            *metricVec
+	Drainstatus                *metricVec
+	StatDrainprogress          *metricVec
+	StatDrainfiles             *metricVec
+	StatDrainbytesleft         *metricVec
+	StatDrainretry             *metricVec
+	StatDrainFailed            *metricVec
+	Graceperiod                *metricVec
+	StatTimeleft               *metricVec
+	StatActive                 *metricVec
+	StatBalancerRunning        *metricVec
+	StatDrainerRunning         *metricVec
+	StatDiskIops               *metricVec
+	StatDiskBw                 *metricVec
+	StatGeotag                 *metricVec
+	StatHealth                 *metricVec
+	StatHealthRedundancyFactor *metricVec
+	StatHealthDrivesFailed     *metricVec
+	StatHealthDrivesTotal      *metricVec
+	StatHealthIndicator        *metricVec
 }
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -69,9 +64,9 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &FSCollector{
+	o := &FSCollector{
 		CollectorOpts: opts,
-		StatBoot: prometheus.NewGaugeVec(
+		StatBoot: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_boot_status",
@@ -80,7 +75,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		Configstatus: prometheus.NewGaugeVec(
+		Configstatus: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_config_status",
@@ -89,7 +84,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDiskLoad: prometheus.NewGaugeVec(
+		StatDiskLoad: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_load",
@@ -98,7 +93,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDiskReadratemb: prometheus.NewGaugeVec(
+		StatDiskReadratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_readratemb",
@@ -107,7 +102,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDiskWriteratemb: prometheus.NewGaugeVec(
+		StatDiskWriteratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_writeratemb",
@@ -116,7 +111,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatNetEthratemib: prometheus.NewGaugeVec(
+		StatNetEthratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_net_ethratemib",
@@ -125,7 +120,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatNetInratemib: prometheus.NewGaugeVec(
+		StatNetInratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_net_inratemib",
@@ -134,7 +129,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatNetOutratemib: prometheus.NewGaugeVec(
+		StatNetOutratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_net_outratemib",
@@ -143,7 +138,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatRopen: prometheus.NewGaugeVec(
+		StatRopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_ropen",
@@ -152,7 +147,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatWopen: prometheus.NewGaugeVec(
+		StatWopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_wopen",
@@ -161,7 +156,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsUsedbytes: prometheus.NewGaugeVec(
+		StatStatfsUsedbytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_usedbytes",
@@ -170,7 +165,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsFreebytes: prometheus.NewGaugeVec(
+		StatStatfsFreebytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_freebytes",
@@ -179,7 +174,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsCapacity: prometheus.NewGaugeVec(
+		StatStatfsCapacity: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_sizebytes",
@@ -188,7 +183,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsFused: prometheus.NewGaugeVec(
+		StatStatfsFused: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_usedfiles",
@@ -197,7 +192,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsFfree: prometheus.NewGaugeVec(
+		StatStatfsFfree: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_freefiles",
@@ -206,7 +201,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatStatfsFiles: prometheus.NewGaugeVec(
+		StatStatfsFiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_statfs_totalfiles",
@@ -215,7 +210,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		Drainstatus: prometheus.NewGaugeVec(
+		Drainstatus: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_status",
@@ -224,7 +219,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainprogress: prometheus.NewGaugeVec(
+		StatDrainprogress: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_progress",
@@ -233,7 +228,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainfiles: prometheus.NewGaugeVec(
+		StatDrainfiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_filesleft",
@@ -242,7 +237,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainbytesleft: prometheus.NewGaugeVec(
+		StatDrainbytesleft: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_bytesleft",
@@ -251,7 +246,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainretry: prometheus.NewGaugeVec(
+		StatDrainretry: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_retries",
@@ -260,7 +255,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainFailed: prometheus.NewGaugeVec(
+		StatDrainFailed: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_failed",
@@ -269,7 +264,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatActive: prometheus.NewGaugeVec(
+		StatActive: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_status",
@@ -278,16 +273,16 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatBalancerRunning: prometheus.NewGaugeVec(
+		StatBalancerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_balancer_running",
 				Help:        "FS Stat Balancer Running",
 				ConstLabels: labels,
 			},
-			[]string{"fs", "node"},
+			[]string{"fs", "node", "geotag"},
 		),
-		StatDrainerRunning: prometheus.NewGaugeVec(
+		StatDrainerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_drain_running",
@@ -296,7 +291,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDiskIops: prometheus.NewGaugeVec(
+		StatDiskIops: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_iops",
@@ -305,7 +300,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatDiskBw: prometheus.NewGaugeVec(
+		StatDiskBw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_disk_bw_MB",
@@ -314,7 +309,7 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			},
 			[]string{"fs", "node", "geotag"},
 		),
-		StatHealth: prometheus.NewGaugeVec(
+		StatHealth: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fs_health",
@@ -324,10 +319,12 @@ func NewFSCollector(opts *CollectorOpts) *FSCollector {
 			[]string{"fs", "node", "geotag"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "fs metrics", collect: o.collectFSDF}
+	return o
 }
 
-func (o *FSCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *FSCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.StatBoot,
 		o.Configstatus,
 		o.StatDiskLoad,
@@ -359,250 +356,127 @@ func (o *FSCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func getEOSInstance() string {
-	// Get the EOS cluster name from MGM's filesystem
-	var str string
+func (o *FSCollector) collectFSDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	file, err := os.Open("/etc/sysconfig/eos_env")
-	if err != nil {
-		fmt.Println(err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		l := scanner.Text()
-		if strings.HasPrefix(l, "EOS_MGM_ALIAS=") {
-			s := strings.Split(l, "EOS_MGM_ALIAS=")
-			str = strings.Replace(s[1], "\"", "", -1)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		fmt.Println(err)
-	}
-
-	return str
-}
-
-func (o *FSCollector) collectFSDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
-
-	mds, err := client.ListFS(context.Background(), "root")
+	mds, err := client.ListFS(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	// Reset Gauge metrics to remove metrics of non existing filesystems
-
-	o.StatBoot.Reset()
-	o.Configstatus.Reset()
-	o.StatDiskLoad.Reset()
-	o.StatDiskReadratemb.Reset()
-	o.StatDiskWriteratemb.Reset()
-	o.StatNetEthratemib.Reset()
-	o.StatNetInratemib.Reset()
-	o.StatNetOutratemib.Reset()
-	o.StatRopen.Reset()
-	o.StatWopen.Reset()
-	o.StatStatfsFreebytes.Reset()
-	o.StatStatfsUsedbytes.Reset()
-	o.StatStatfsCapacity.Reset()
-	o.StatStatfsFfree.Reset()
-	o.StatStatfsFused.Reset()
-	o.StatStatfsFiles.Reset()
-	o.Drainstatus.Reset()
-	o.StatDrainprogress.Reset()
-	o.StatDrainfiles.Reset()
-	o.StatDrainbytesleft.Reset()
-	o.StatDrainretry.Reset()
-	o.StatDrainFailed.Reset()
-	o.StatActive.Reset()
-	o.StatBalancerRunning.Reset()
-	o.StatDrainerRunning.Reset()
-	o.StatDiskIops.Reset()
-	o.StatDiskBw.Reset()
-	o.StatHealth.Reset()
-
 	for _, m := range mds {
 
 		// Boot Status
 
 		boot_status := 0
-		switch stat := m.StatBoot; stat {
-		case "booted":
+		switch m.StatBoot {
+		case eosclient.BootBooted:
 			boot_status = 0
-		case "booting":
+		case eosclient.BootBooting:
 			boot_status = 1
-		case "bootfailure":
+		case eosclient.BootFailure:
 			boot_status = 2
-		case "opserror":
+		case eosclient.BootOpsError:
 			boot_status = 3
-		case "down":
+		case eosclient.BootDown:
 			boot_status = 4
 		default:
 			boot_status = 4
 		}
 
-		o.StatBoot.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(float64(boot_status))
+		b.set(o.StatBoot, float64(boot_status), m.Id, m.Host, m.StatGeotag)
 
 		// Config Status
 
 		config_status := 0
-		switch stat := m.Configstatus; stat {
-		case "rw":
+		switch m.Configstatus {
+		case eosclient.ConfigRW:
 			config_status = 0
-		case "ro":
+		case eosclient.ConfigRO:
 			config_status = 1
-		case "drain":
+		case eosclient.ConfigDrain:
 			config_status = 2
-		case "empty":
+		case eosclient.ConfigEmpty:
 			config_status = 3
 		default:
 			config_status = 0
 		}
 
-		o.Configstatus.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(float64(config_status))
+		b.set(o.Configstatus, float64(config_status), m.Id, m.Host, m.StatGeotag)
 
-		diskload, err := strconv.ParseFloat(m.StatDiskLoad, 64)
-		if err == nil {
-			o.StatDiskLoad.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(diskload)
-		}
+		setValue(b, o.StatDiskLoad, m.StatDiskLoad, m.Id, m.Host, m.StatGeotag)
 
-		diskr, err := strconv.ParseFloat(m.StatDiskReadratemb, 64)
-		if err == nil {
-			o.StatDiskReadratemb.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(diskr)
-		}
+		setValue(b, o.StatDiskReadratemb, m.StatDiskReadratemb, m.Id, m.Host, m.StatGeotag)
 
-		diskw, err := strconv.ParseFloat(m.StatDiskWriteratemb, 64)
-		if err == nil {
-			o.StatDiskWriteratemb.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(diskw)
-		}
+		setValue(b, o.StatDiskWriteratemb, m.StatDiskWriteratemb, m.Id, m.Host, m.StatGeotag)
 
-		ethrate, err := strconv.ParseFloat(m.StatNetEthratemib, 64)
-		if err == nil {
-			o.StatNetEthratemib.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(ethrate)
-		}
+		setValue(b, o.StatNetEthratemib, m.StatNetEthratemib, m.Id, m.Host, m.StatGeotag)
 
-		inrate, err := strconv.ParseFloat(m.StatNetInratemib, 64)
-		if err == nil {
-			o.StatNetInratemib.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(inrate)
-		}
+		setValue(b, o.StatNetInratemib, m.StatNetInratemib, m.Id, m.Host, m.StatGeotag)
 
-		outrate, err := strconv.ParseFloat(m.StatNetOutratemib, 64)
-		if err == nil {
-			o.StatNetOutratemib.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(outrate)
-		}
+		setValue(b, o.StatNetOutratemib, m.StatNetOutratemib, m.Id, m.Host, m.StatGeotag)
 
-		ropen, err := strconv.ParseFloat(m.StatRopen, 64)
-		if err == nil {
-			o.StatRopen.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(ropen)
-		}
+		setValue(b, o.StatRopen, m.StatRopen, m.Id, m.Host, m.StatGeotag)
 
-		wopen, err := strconv.ParseFloat(m.StatWopen, 64)
-		if err == nil {
-			o.StatWopen.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(wopen)
-		}
+		setValue(b, o.StatWopen, m.StatWopen, m.Id, m.Host, m.StatGeotag)
 
-		usedb, err := strconv.ParseFloat(m.StatStatfsUsedbytes, 64)
-		if err == nil {
-			o.StatStatfsUsedbytes.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(usedb)
-		}
+		setValue(b, o.StatStatfsUsedbytes, m.StatStatfsUsedbytes, m.Id, m.Host, m.StatGeotag)
 
-		fbytes, err := strconv.ParseFloat(m.StatStatfsFreebytes, 64)
-		if err == nil {
-			o.StatStatfsFreebytes.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(fbytes)
-		}
+		setValue(b, o.StatStatfsFreebytes, m.StatStatfsFreebytes, m.Id, m.Host, m.StatGeotag)
 
-		fscap, err := strconv.ParseFloat(m.StatStatfsCapacity, 64)
-		if err == nil {
-			o.StatStatfsCapacity.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(fscap)
-		}
+		setValue(b, o.StatStatfsCapacity, m.StatStatfsCapacity, m.Id, m.Host, m.StatGeotag)
 
-		ufiles, err := strconv.ParseFloat(m.StatStatfsFused, 64)
-		if err == nil {
-			o.StatStatfsFused.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(ufiles)
-		}
+		setValue(b, o.StatStatfsFused, m.StatStatfsFused, m.Id, m.Host, m.StatGeotag)
 
-		ffree, err := strconv.ParseFloat(m.StatStatfsFfree, 64)
-		if err == nil {
-			o.StatStatfsFfree.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(ffree)
-		}
+		setValue(b, o.StatStatfsFfree, m.StatStatfsFfree, m.Id, m.Host, m.StatGeotag)
 
-		files, err := strconv.ParseFloat(m.StatStatfsFiles, 64)
-		if err == nil {
-			o.StatStatfsFiles.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(files)
-		}
+		setValue(b, o.StatStatfsFiles, m.StatStatfsFiles, m.Id, m.Host, m.StatGeotag)
 
 		// Drain Status.
 
 		drain_status := 0
-		switch stat := m.Drainstatus; stat {
-		case "nodrain":
+		switch m.Drainstatus {
+		case eosclient.DrainNone:
 			drain_status = 0
-		case "drained":
+		case eosclient.DrainDrained:
 			drain_status = 1
-		case "draining":
+		case eosclient.DrainDraining:
 			drain_status = 2
-		case "stalling":
+		case eosclient.DrainStalling:
 			drain_status = 3
-		case "expired":
+		case eosclient.DrainExpired:
 			drain_status = 4
 		default:
 			drain_status = 0
 		}
 
-		o.Drainstatus.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(float64(drain_status))
+		b.set(o.Drainstatus, float64(drain_status), m.Id, m.Host, m.StatGeotag)
 
-		balr, err := strconv.ParseFloat(m.StatBalancerRunning, 64)
-		if err == nil {
-			o.StatBalancerRunning.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(balr)
-		}
+		setValue(b, o.StatBalancerRunning, m.StatBalancerRunning, m.Id, m.Host, m.StatGeotag)
 
-		drainr, err := strconv.ParseFloat(m.StatDrainerRunning, 64)
-		if err == nil {
-			o.StatDrainerRunning.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(drainr)
-		}
+		setValue(b, o.StatDrainerRunning, m.StatDrainerRunning, m.Id, m.Host, m.StatGeotag)
 
-		drainretry, err := strconv.ParseFloat(m.StatDrainretry, 64)
-		if err == nil {
-			o.StatDrainretry.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(drainretry)
-		}
+		setValue(b, o.StatDrainretry, m.StatDrainretry, m.Id, m.Host, m.StatGeotag)
 
-		drainfailed, err := strconv.ParseFloat(m.StatDrainFailed, 64)
-		if err == nil {
-			o.StatDrainFailed.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(drainfailed)
-		}
+		setValue(b, o.StatDrainFailed, m.StatDrainFailed, m.Id, m.Host, m.StatGeotag)
 
-		diskiops, err := strconv.ParseFloat(m.StatDiskIops, 64)
-		if err == nil {
-			o.StatDiskIops.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(diskiops)
-		}
+		setValue(b, o.StatDiskIops, m.StatDiskIops, m.Id, m.Host, m.StatGeotag)
 
-		diskbw, err := strconv.ParseFloat(m.StatDiskBw, 64)
-		if err == nil {
-			o.StatDiskBw.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(diskbw)
-		}
+		setValue(b, o.StatDiskBw, m.StatDiskBw, m.Id, m.Host, m.StatGeotag)
 
 		// FS Active Status.
 
 		active_status := 0
-		switch stat := m.StatActive; stat {
-		case "offline":
+		switch m.StatActive {
+		case eosclient.Offline:
 			active_status = 0
-		case "online":
+		case eosclient.Online:
 			active_status = 1
 		default:
 			active_status = 1
 		}
 
-		o.StatActive.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(float64(active_status))
+		b.set(o.StatActive, float64(active_status), m.Id, m.Host, m.StatGeotag)
 
 		// Health
 
@@ -612,7 +486,7 @@ func (o *FSCollector) collectFSDF() error {
 		} else {
 			health = 1
 		}
-		o.StatHealth.WithLabelValues(m.Id, m.Host, m.StatGeotag).Set(float64(health))
+		b.set(o.StatHealth, float64(health), m.Id, m.Host, m.StatGeotag)
 	}
 
 	return nil
@@ -626,16 +500,3 @@ func (o *FSCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 	//ch <- o.ScrubbingStateDesc
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *FSCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectFSDF(); err != nil {
-		log.Println("failed collecting fs metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
@@ -2,16 +2,15 @@ package collector
 
 import (
 	"context"
-	"log"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/cern-eos/eos_exporter/eosclient"
 )
 
 type FsckCollector struct {
 	*CollectorOpts
-	Count *prometheus.GaugeVec
+	batchCollector
+	Count *metricVec
 }
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -21,9 +20,9 @@ func NewFsckCollector(opts *CollectorOpts) *FsckCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &FsckCollector{
+	o := &FsckCollector{
 		CollectorOpts: opts,
-		Count: prometheus.NewGaugeVec(
+		Count: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fsck_stat",
@@ -33,10 +32,12 @@ func NewFsckCollector(opts *CollectorOpts) *FsckCollector {
 			[]string{"tag"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "fsck metrics", collect: o.collectFsckDF}
+	return o
 }
 
-func (o *FsckCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *FsckCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Count,
 	}
 }
@@ -67,27 +68,19 @@ func (o *FsckCollector) collectorList() []prometheus.Collector {
 // 	return str
 // }
 
-func (o *FsckCollector) collectFsckDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *FsckCollector) collectFsckDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.FsckReport(context.Background(), "root")
+	mds, err := client.FsckReport(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Count.Reset()
-
 	for _, m := range mds {
 
 		count, err := strconv.ParseFloat(m.Count, 64)
 		if err == nil {
-			o.Count.WithLabelValues(m.Tag).Set(count)
+			b.set(o.Count, count, m.Tag)
 		}
 	}
 
@@ -102,16 +95,3 @@ func (o *FsckCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 	//ch <- o.ScrubbingStateDesc
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *FsckCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectFsckDF(); err != nil {
-		log.Println("failed collecting fsck metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
@@ -2,10 +2,8 @@ package collector
 
 import (
 	"context"
-	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/cern-eos/eos_exporter/eosclient"
 )
 
 // sample line
@@ -59,7 +57,8 @@ blockedfunc=none
 
 type FusexCollector struct {
 	*CollectorOpts
-	Info *prometheus.GaugeVec
+	batchCollector
+	Info *metricVec
 }
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -69,9 +68,9 @@ func NewFusexCollector(opts *CollectorOpts) *FusexCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &FusexCollector{
+	o := &FusexCollector{
 		CollectorOpts: opts,
-		Info: prometheus.NewGaugeVec(
+		Info: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "fusex_info",
@@ -81,33 +80,27 @@ func NewFusexCollector(opts *CollectorOpts) *FusexCollector {
 			[]string{"host", "version"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "fusex metrics", collect: o.collectFusexDF}
+	return o
 }
 
-func (o *FusexCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *FusexCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Info,
 	}
 }
 
-func (o *FusexCollector) collectFusexDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *FusexCollector) collectFusexDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListFusex(context.Background(), "root")
+	mds, err := client.ListFusex(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Info.Reset()
-
 	for _, m := range mds {
 		// We just send a dummy 1 as value
-		o.Info.WithLabelValues(m.Host, m.Version).Set(1)
+		b.set(o.Info, 1, m.Host, m.Version)
 	}
 
 	return nil
@@ -121,16 +114,3 @@ func (o *FusexCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 	//ch <- o.ScrubbingStateDesc
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *FusexCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectFusexDF(); err != nil {
-		log.Println("failed collecting fsck metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
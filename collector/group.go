@@ -2,39 +2,38 @@ package collector
 
 import (
 	"context"
-	"log"
-	"strconv"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/cern-eos/eos_exporter/eosclient"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type GroupCollector struct {
 	*CollectorOpts
-	Name                   *prometheus.GaugeVec
-	CfgStatus              *prometheus.GaugeVec
-	Nofs                   *prometheus.GaugeVec
-	AvgStatDiskLoad        *prometheus.GaugeVec
-	SigStatDiskLoad        *prometheus.GaugeVec
-	SumStatDiskReadratemb  *prometheus.GaugeVec
-	SumStatDiskWriteratemb *prometheus.GaugeVec
-	SumStatNetEthratemib   *prometheus.GaugeVec
-	SumStatNetInratemib    *prometheus.GaugeVec
-	SumStatNetOutratemib   *prometheus.GaugeVec
-	SumStatRopen           *prometheus.GaugeVec
-	SumStatWopen           *prometheus.GaugeVec
-	SumStatStatfsUsedbytes *prometheus.GaugeVec
-	SumStatStatfsFreebytes *prometheus.GaugeVec
-	SumStatStatfsCapacity  *prometheus.GaugeVec
-	SumStatUsedfiles       *prometheus.GaugeVec
-	SumStatStatfsFfree     *prometheus.GaugeVec
-	SumStatStatfsFiles     *prometheus.GaugeVec
-	DevStatStatfsFilled    *prometheus.GaugeVec
-	AvgStatStatfsFilled    *prometheus.GaugeVec
-	SigStatStatfsFilled    *prometheus.GaugeVec
-	CfgStatBalancing       *prometheus.GaugeVec
-	SumStatBalancerRunning *prometheus.GaugeVec
-	SumStatDrainerRunning  *prometheus.GaugeVec
+	batchCollector
+	Name                   *metricVec
+	CfgStatus              *metricVec
+	Nofs                   *metricVec
+	AvgStatDiskLoad        *metricVec
+	SigStatDiskLoad        *metricVec
+	SumStatDiskReadratemb  *metricVec
+	SumStatDiskWriteratemb *metricVec
+	SumStatNetEthratemib   *metricVec
+	SumStatNetInratemib    *metricVec
+	SumStatNetOutratemib   *metricVec
+	SumStatRopen           *metricVec
+	SumStatWopen           *metricVec
+	SumStatStatfsUsedbytes *metricVec
+	SumStatStatfsFreebytes *metricVec
+	SumStatStatfsCapacity  *metricVec
+	SumStatUsedfiles       *metricVec
+	SumStatStatfsFfree     *metricVec
+	SumStatStatfsFiles     *metricVec
+	DevStatStatfsFilled    *metricVec
+	AvgStatStatfsFilled    *metricVec
+	SigStatStatfsFilled    *metricVec
+	CfgStatBalancing       *metricVec
+	SumStatBalancerRunning *metricVec
+	SumStatDrainerRunning  *metricVec
 }
 
 // NewGroupCollector creates an cluster of the GroupCollector and instantiates
@@ -44,9 +43,9 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &GroupCollector{
+	o := &GroupCollector{
 		CollectorOpts: opts,
-		CfgStatus: prometheus.NewGaugeVec(
+		CfgStatus: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_cfg_status",
@@ -55,7 +54,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		Nofs: prometheus.NewGaugeVec(
+		Nofs: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_nofs",
@@ -64,7 +63,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		AvgStatDiskLoad: prometheus.NewGaugeVec(
+		AvgStatDiskLoad: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_load_avg",
@@ -73,7 +72,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SigStatDiskLoad: prometheus.NewGaugeVec(
+		SigStatDiskLoad: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_load_sig",
@@ -82,7 +81,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatDiskReadratemb: prometheus.NewGaugeVec(
+		SumStatDiskReadratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_readratemb",
@@ -91,7 +90,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatDiskWriteratemb: prometheus.NewGaugeVec(
+		SumStatDiskWriteratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_writeratemb",
@@ -100,7 +99,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatNetEthratemib: prometheus.NewGaugeVec(
+		SumStatNetEthratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_net_ethratemib",
@@ -109,7 +108,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatNetInratemib: prometheus.NewGaugeVec(
+		SumStatNetInratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_net_inratemib",
@@ -118,7 +117,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatNetOutratemib: prometheus.NewGaugeVec(
+		SumStatNetOutratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_net_outratemib",
@@ -127,7 +126,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatRopen: prometheus.NewGaugeVec(
+		SumStatRopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_ropen",
@@ -136,7 +135,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatWopen: prometheus.NewGaugeVec(
+		SumStatWopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_disk_wopen",
@@ -145,7 +144,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatStatfsUsedbytes: prometheus.NewGaugeVec(
+		SumStatStatfsUsedbytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_usedbytes",
@@ -154,7 +153,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatStatfsFreebytes: prometheus.NewGaugeVec(
+		SumStatStatfsFreebytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_freebytes",
@@ -163,7 +162,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatStatfsCapacity: prometheus.NewGaugeVec(
+		SumStatStatfsCapacity: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_sizebytes",
@@ -172,7 +171,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatUsedfiles: prometheus.NewGaugeVec(
+		SumStatUsedfiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_usedfiles",
@@ -181,7 +180,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatStatfsFfree: prometheus.NewGaugeVec(
+		SumStatStatfsFfree: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_freefiles",
@@ -190,7 +189,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatStatfsFiles: prometheus.NewGaugeVec(
+		SumStatStatfsFiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_totalfiles",
@@ -199,7 +198,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		DevStatStatfsFilled: prometheus.NewGaugeVec(
+		DevStatStatfsFilled: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_filled_dev",
@@ -208,7 +207,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		AvgStatStatfsFilled: prometheus.NewGaugeVec(
+		AvgStatStatfsFilled: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_filled_avg",
@@ -217,7 +216,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SigStatStatfsFilled: prometheus.NewGaugeVec(
+		SigStatStatfsFilled: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "group_statfs_filled_sig",
@@ -226,7 +225,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		CfgStatBalancing: prometheus.NewGaugeVec(
+		CfgStatBalancing: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "group_balancer_status",
@@ -235,7 +234,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatBalancerRunning: prometheus.NewGaugeVec(
+		SumStatBalancerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "group_balancer_running",
@@ -244,7 +243,7 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			},
 			[]string{"group"},
 		),
-		SumStatDrainerRunning: prometheus.NewGaugeVec(
+		SumStatDrainerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "group_drainer_running",
@@ -254,10 +253,12 @@ func NewGroupCollector(opts *CollectorOpts) *GroupCollector {
 			[]string{"group"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "group metrics", collect: o.collectGroupDF}
+	return o
 }
 
-func (o *GroupCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *GroupCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.CfgStatus,
 		o.Nofs,
 		o.AvgStatDiskLoad,
@@ -284,176 +285,75 @@ func (o *GroupCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *GroupCollector) collectGroupDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *GroupCollector) collectGroupDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListGroup(context.Background(), "root")
+	mds, err := client.ListGroup(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	// Reset gauge metrics to remove metrics of deleted groups
-
-	o.CfgStatus.Reset()
-	o.Nofs.Reset()
-	o.AvgStatDiskLoad.Reset()
-	o.SigStatDiskLoad.Reset()
-	o.SumStatDiskReadratemb.Reset()
-	o.SumStatDiskWriteratemb.Reset()
-	o.SumStatNetEthratemib.Reset()
-	o.SumStatNetInratemib.Reset()
-	o.SumStatNetOutratemib.Reset()
-	o.SumStatRopen.Reset()
-	o.SumStatWopen.Reset()
-	o.SumStatStatfsUsedbytes.Reset()
-	o.SumStatStatfsFreebytes.Reset()
-	o.SumStatStatfsCapacity.Reset()
-	o.SumStatUsedfiles.Reset()
-	o.SumStatStatfsFfree.Reset()
-	o.SumStatStatfsFiles.Reset()
-	o.DevStatStatfsFilled.Reset()
-	o.AvgStatStatfsFilled.Reset()
-	o.SigStatStatfsFilled.Reset()
-	o.CfgStatBalancing.Reset()
-	o.SumStatBalancerRunning.Reset()
-	o.SumStatDrainerRunning.Reset()
-
 	for _, m := range mds {
 
-		cfgstatus := 0
-		if m.CfgStatus == "on" {
-			cfgstatus = 1
-		}
+		b.set(o.CfgStatus, boolValue(m.CfgStatus), m.Name)
 
-		status := float64(cfgstatus)
-		o.CfgStatus.WithLabelValues(m.Name).Set(status)
+		setValue(b, o.Nofs, m.Nofs, m.Name)
 
-		nofs, err := strconv.ParseFloat(m.Nofs, 64)
-		if err == nil {
-			o.Nofs.WithLabelValues(m.Name).Set(nofs)
-		}
+		setValue(b, o.AvgStatDiskLoad, m.AvgStatDiskLoad, m.Name)
 
-		avgdl, err := strconv.ParseFloat(m.AvgStatDiskLoad, 64)
-		if err == nil {
-			o.AvgStatDiskLoad.WithLabelValues(m.Name).Set(avgdl)
-		}
+		setValue(b, o.SigStatDiskLoad, m.SigStatDiskLoad, m.Name)
 
-		sigdl, err := strconv.ParseFloat(m.SigStatDiskLoad, 64)
-		if err == nil {
-			o.SigStatDiskLoad.WithLabelValues(m.Name).Set(sigdl)
-		}
+		setValue(b, o.SumStatDiskReadratemb, m.SumStatDiskReadratemb, m.Name)
 
-		sumdiskr, err := strconv.ParseFloat(m.SumStatDiskReadratemb, 64)
-		if err == nil {
-			o.SumStatDiskReadratemb.WithLabelValues(m.Name).Set(sumdiskr)
-		}
+		setValue(b, o.SumStatDiskWriteratemb, m.SumStatDiskWriteratemb, m.Name)
 
-		sumdiskw, err := strconv.ParseFloat(m.SumStatDiskWriteratemb, 64)
-		if err == nil {
-			o.SumStatDiskWriteratemb.WithLabelValues(m.Name).Set(sumdiskw)
-		}
+		setValue(b, o.SumStatNetEthratemib, m.SumStatNetEthratemib, m.Name)
 
-		sumethrate, err := strconv.ParseFloat(m.SumStatNetEthratemib, 64)
-		if err == nil {
-			o.SumStatNetEthratemib.WithLabelValues(m.Name).Set(sumethrate)
-		}
+		setValue(b, o.SumStatNetInratemib, m.SumStatNetInratemib, m.Name)
 
-		suminrate, err := strconv.ParseFloat(m.SumStatNetInratemib, 64)
-		if err == nil {
-			o.SumStatNetInratemib.WithLabelValues(m.Name).Set(suminrate)
-		}
+		setValue(b, o.SumStatNetOutratemib, m.SumStatNetOutratemib, m.Name)
 
-		sumoutrate, err := strconv.ParseFloat(m.SumStatNetOutratemib, 64)
-		if err == nil {
-			o.SumStatNetOutratemib.WithLabelValues(m.Name).Set(sumoutrate)
-		}
+		setValue(b, o.SumStatRopen, m.SumStatRopen, m.Name)
 
-		ropen, err := strconv.ParseFloat(m.SumStatRopen, 64)
-		if err == nil {
-			o.SumStatRopen.WithLabelValues(m.Name).Set(ropen)
-		}
+		setValue(b, o.SumStatWopen, m.SumStatWopen, m.Name)
 
-		wopen, err := strconv.ParseFloat(m.SumStatWopen, 64)
-		if err == nil {
-			o.SumStatWopen.WithLabelValues(m.Name).Set(wopen)
-		}
+		setValue(b, o.SumStatStatfsUsedbytes, m.SumStatStatfsUsedbytes, m.Name)
 
-		usedb, err := strconv.ParseFloat(m.SumStatStatfsUsedbytes, 64)
-		if err == nil {
-			o.SumStatStatfsUsedbytes.WithLabelValues(m.Name).Set(usedb)
-		}
+		setValue(b, o.SumStatStatfsFreebytes, m.SumStatStatfsFreebytes, m.Name)
 
-		fbytes, err := strconv.ParseFloat(m.SumStatStatfsFreebytes, 64)
-		if err == nil {
-			o.SumStatStatfsFreebytes.WithLabelValues(m.Name).Set(fbytes)
-		}
+		setValue(b, o.SumStatStatfsCapacity, m.SumStatStatfsCapacity, m.Name)
 
-		fscap, err := strconv.ParseFloat(m.SumStatStatfsCapacity, 64)
-		if err == nil {
-			o.SumStatStatfsCapacity.WithLabelValues(m.Name).Set(fscap)
-		}
+		setValue(b, o.SumStatUsedfiles, m.SumStatUsedfiles, m.Name)
 
-		ufiles, err := strconv.ParseFloat(m.SumStatUsedfiles, 64)
-		if err == nil {
-			o.SumStatUsedfiles.WithLabelValues(m.Name).Set(ufiles)
-		}
+		setValue(b, o.SumStatStatfsFfree, m.SumStatStatfsFfree, m.Name)
 
-		ffree, err := strconv.ParseFloat(m.SumStatStatfsFfree, 64)
-		if err == nil {
-			o.SumStatStatfsFfree.WithLabelValues(m.Name).Set(ffree)
-		}
+		setValue(b, o.SumStatStatfsFiles, m.SumStatStatfsFiles, m.Name)
 
-		files, err := strconv.ParseFloat(m.SumStatStatfsFiles, 64)
-		if err == nil {
-			o.SumStatStatfsFiles.WithLabelValues(m.Name).Set(files)
-		}
+		setValue(b, o.DevStatStatfsFilled, m.DevStatStatfsFilled, m.Name)
 
-		devfilled, err := strconv.ParseFloat(m.DevStatStatfsFilled, 64)
-		if err == nil {
-			o.DevStatStatfsFilled.WithLabelValues(m.Name).Set(devfilled)
-		}
+		setValue(b, o.AvgStatStatfsFilled, m.AvgStatStatfsFilled, m.Name)
 
-		avgfilled, err := strconv.ParseFloat(m.AvgStatStatfsFilled, 64)
-		if err == nil {
-			o.AvgStatStatfsFilled.WithLabelValues(m.Name).Set(avgfilled)
-		}
-
-		sigfilled, err := strconv.ParseFloat(m.SigStatStatfsFilled, 64)
-		if err == nil {
-			o.SigStatStatfsFilled.WithLabelValues(m.Name).Set(sigfilled)
-		}
+		setValue(b, o.SigStatStatfsFilled, m.SigStatStatfsFilled, m.Name)
 
 		// Balancer Status.
 
 		balancer_status := 0
-		switch stat := m.CfgStatBalancing; stat {
-		case "idle":
+		switch m.CfgStatBalancing {
+		case eosclient.BalancingIdle:
 			balancer_status = 0
-		case "balancing":
+		case eosclient.BalancingBalancing:
 			balancer_status = 1
-		case "drainwait":
+		case eosclient.BalancingDrainWait:
 			balancer_status = 2
 		default:
 			balancer_status = 0
 		}
 
-		o.CfgStatBalancing.WithLabelValues(m.Name).Set(float64(balancer_status))
+		b.set(o.CfgStatBalancing, float64(balancer_status), m.Name)
 
-		balr, err := strconv.ParseFloat(m.SumStatBalancerRunning, 64)
-		if err == nil {
-			o.SumStatBalancerRunning.WithLabelValues(m.Name).Set(balr)
-		}
+		setValue(b, o.SumStatBalancerRunning, m.SumStatBalancerRunning, m.Name)
 
-		drainr, err := strconv.ParseFloat(m.SumStatDrainerRunning, 64)
-		if err == nil {
-			o.SumStatDrainerRunning.WithLabelValues(m.Name).Set(drainr)
-		}
+		setValue(b, o.SumStatDrainerRunning, m.SumStatDrainerRunning, m.Name)
 	}
 
 	return nil
@@ -466,16 +366,3 @@ func (o *GroupCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *GroupCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectGroupDF(); err != nil {
-		log.Println("failed collecting group metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
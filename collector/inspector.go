@@ -2,46 +2,51 @@ package collector
 
 import (
 	"context"
-	"log"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/cern-eos/eos_exporter/eosclient"
 )
 
 type InspectorLayoutCollector struct {
 	*CollectorOpts
-	Volume *prometheus.GaugeVec
+	batchCollector
+	Volume *metricVec
 }
 
 type InspectorAccessTimeVolumeCollector struct {
 	*CollectorOpts
-	Volume *prometheus.GaugeVec
+	batchCollector
+	Volume *metricVec
 }
 
 type InspectorAccessTimeFilesCollector struct {
 	*CollectorOpts
-	Files *prometheus.GaugeVec
+	batchCollector
+	Files *metricVec
 }
 
 type InspectorBirthTimeVolumeCollector struct {
 	*CollectorOpts
-	Volume *prometheus.GaugeVec
+	batchCollector
+	Volume *metricVec
 }
 
 type InspectorBirthTimeFilesCollector struct {
 	*CollectorOpts
-	Files *prometheus.GaugeVec
+	batchCollector
+	Files *metricVec
 }
 
 type InspectorGroupCostDiskCollector struct {
 	*CollectorOpts
-	Cost *prometheus.GaugeVec
+	batchCollector
+	Cost *metricVec
 }
 
 type InspectorGroupCostDiskTBYearsCollector struct {
 	*CollectorOpts
-	TBYears *prometheus.GaugeVec
+	batchCollector
+	TBYears *metricVec
 }
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -51,9 +56,9 @@ func NewInspectorLayoutCollector(opts *CollectorOpts) *InspectorLayoutCollector
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorLayoutCollector{
+	o := &InspectorLayoutCollector{
 		CollectorOpts: opts,
-		Volume: prometheus.NewGaugeVec(
+		Volume: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_layout_volume_bytes",
@@ -63,35 +68,29 @@ func NewInspectorLayoutCollector(opts *CollectorOpts) *InspectorLayoutCollector
 			[]string{"layout", "type", "nominal_stripes", "blocksize"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics", collect: o.collectInspectorLayoutDF}
+	return o
 }
 
-func (o *InspectorLayoutCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorLayoutCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Volume,
 	}
 }
 
-func (o *InspectorLayoutCollector) collectInspectorLayoutDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorLayoutCollector) collectInspectorLayoutDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorLayout(context.Background(), "root")
+	mds, err := client.ListInspectorLayout(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Volume.Reset()
-
 	for _, m := range mds {
 
 		volume, err := strconv.ParseFloat(m.Volume, 64)
 		if err == nil {
-			o.Volume.WithLabelValues(m.Layout, m.Type, m.NominalStripes, m.BlockSize).Set(volume)
+			b.set(o.Volume, volume, m.Layout, m.Type, m.NominalStripes, m.BlockSize)
 		}
 	}
 
@@ -107,19 +106,6 @@ func (o *InspectorLayoutCollector) Describe(ch chan<- *prometheus.Desc) {
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorLayoutCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorLayoutDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // NewFSCollector creates an cluster of the FSCollector and instantiates
 // the individual metrics that show information about the FS.
 func NewInspectorAccessTimeVolumeCollector(opts *CollectorOpts) *InspectorAccessTimeVolumeCollector {
@@ -127,9 +113,9 @@ func NewInspectorAccessTimeVolumeCollector(opts *CollectorOpts) *InspectorAccess
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorAccessTimeVolumeCollector{
+	o := &InspectorAccessTimeVolumeCollector{
 		CollectorOpts: opts,
-		Volume: prometheus.NewGaugeVec(
+		Volume: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_accesstime_volume_bytes",
@@ -139,35 +125,29 @@ func NewInspectorAccessTimeVolumeCollector(opts *CollectorOpts) *InspectorAccess
 			[]string{"bin"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (accesstime volume)", collect: o.collectInspectorAccessTimeVolumeDF}
+	return o
 }
 
-func (o *InspectorAccessTimeVolumeCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorAccessTimeVolumeCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Volume,
 	}
 }
 
-func (o *InspectorAccessTimeVolumeCollector) collectInspectorAccessTimeVolumeDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorAccessTimeVolumeCollector) collectInspectorAccessTimeVolumeDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorAccessTimeVolume(context.Background(), "root")
+	mds, err := client.ListInspectorAccessTimeVolume(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Volume.Reset()
-
 	for _, m := range mds {
 
 		volume, err := strconv.ParseFloat(m.Volume, 64)
 		if err == nil {
-			o.Volume.WithLabelValues(m.Bin).Set(volume)
+			b.set(o.Volume, volume, m.Bin)
 		}
 	}
 
@@ -183,19 +163,6 @@ func (o *InspectorAccessTimeVolumeCollector) Describe(ch chan<- *prometheus.Desc
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorAccessTimeVolumeCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorAccessTimeVolumeDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (accesstime volume):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // NewFSCollector creates an cluster of the FSCollector and instantiates
 // the individual metrics that show information about the FS.
 func NewInspectorAccessTimeFilesCollector(opts *CollectorOpts) *InspectorAccessTimeFilesCollector {
@@ -203,9 +170,9 @@ func NewInspectorAccessTimeFilesCollector(opts *CollectorOpts) *InspectorAccessT
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorAccessTimeFilesCollector{
+	o := &InspectorAccessTimeFilesCollector{
 		CollectorOpts: opts,
-		Files: prometheus.NewGaugeVec(
+		Files: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_accesstime_files",
@@ -215,35 +182,29 @@ func NewInspectorAccessTimeFilesCollector(opts *CollectorOpts) *InspectorAccessT
 			[]string{"bin"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (accesstime files)", collect: o.collectInspectorAccessTimeFilesDF}
+	return o
 }
 
-func (o *InspectorAccessTimeFilesCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorAccessTimeFilesCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Files,
 	}
 }
 
-func (o *InspectorAccessTimeFilesCollector) collectInspectorAccessTimeFilesDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorAccessTimeFilesCollector) collectInspectorAccessTimeFilesDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorAccessTimeFiles(context.Background(), "root")
+	mds, err := client.ListInspectorAccessTimeFiles(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Files.Reset()
-
 	for _, m := range mds {
 
 		files, err := strconv.ParseFloat(m.Files, 64)
 		if err == nil {
-			o.Files.WithLabelValues(m.Bin).Set(files)
+			b.set(o.Files, files, m.Bin)
 		}
 	}
 
@@ -259,19 +220,6 @@ func (o *InspectorAccessTimeFilesCollector) Describe(ch chan<- *prometheus.Desc)
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorAccessTimeFilesCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorAccessTimeFilesDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (accestime files):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // Birthtime
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -281,9 +229,9 @@ func NewInspectorBirthTimeVolumeCollector(opts *CollectorOpts) *InspectorBirthTi
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorBirthTimeVolumeCollector{
+	o := &InspectorBirthTimeVolumeCollector{
 		CollectorOpts: opts,
-		Volume: prometheus.NewGaugeVec(
+		Volume: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_birthtime_volume_bytes",
@@ -293,35 +241,29 @@ func NewInspectorBirthTimeVolumeCollector(opts *CollectorOpts) *InspectorBirthTi
 			[]string{"bin"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (birthtime volume)", collect: o.collectInspectorBirthTimeVolumeDF}
+	return o
 }
 
-func (o *InspectorBirthTimeVolumeCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorBirthTimeVolumeCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Volume,
 	}
 }
 
-func (o *InspectorBirthTimeVolumeCollector) collectInspectorBirthTimeVolumeDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorBirthTimeVolumeCollector) collectInspectorBirthTimeVolumeDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorBirthTimeVolume(context.Background(), "root")
+	mds, err := client.ListInspectorBirthTimeVolume(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Volume.Reset()
-
 	for _, m := range mds {
 
 		volume, err := strconv.ParseFloat(m.Volume, 64)
 		if err == nil {
-			o.Volume.WithLabelValues(m.Bin).Set(volume)
+			b.set(o.Volume, volume, m.Bin)
 		}
 	}
 
@@ -337,19 +279,6 @@ func (o *InspectorBirthTimeVolumeCollector) Describe(ch chan<- *prometheus.Desc)
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorBirthTimeVolumeCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorBirthTimeVolumeDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (birthtime volume):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // NewFSCollector creates an cluster of the FSCollector and instantiates
 // the individual metrics that show information about the FS.
 func NewInspectorBirthTimeFilesCollector(opts *CollectorOpts) *InspectorBirthTimeFilesCollector {
@@ -357,9 +286,9 @@ func NewInspectorBirthTimeFilesCollector(opts *CollectorOpts) *InspectorBirthTim
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorBirthTimeFilesCollector{
+	o := &InspectorBirthTimeFilesCollector{
 		CollectorOpts: opts,
-		Files: prometheus.NewGaugeVec(
+		Files: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_birhttime_files",
@@ -369,35 +298,29 @@ func NewInspectorBirthTimeFilesCollector(opts *CollectorOpts) *InspectorBirthTim
 			[]string{"bin"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (birthtime files)", collect: o.collectInspectorBirthTimeFilesDF}
+	return o
 }
 
-func (o *InspectorBirthTimeFilesCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorBirthTimeFilesCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Files,
 	}
 }
 
-func (o *InspectorBirthTimeFilesCollector) collectInspectorBirthTimeFilesDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorBirthTimeFilesCollector) collectInspectorBirthTimeFilesDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorBirthTimeFiles(context.Background(), "root")
+	mds, err := client.ListInspectorBirthTimeFiles(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Files.Reset()
-
 	for _, m := range mds {
 
 		files, err := strconv.ParseFloat(m.Files, 64)
 		if err == nil {
-			o.Files.WithLabelValues(m.Bin).Set(files)
+			b.set(o.Files, files, m.Bin)
 		}
 	}
 
@@ -413,19 +336,6 @@ func (o *InspectorBirthTimeFilesCollector) Describe(ch chan<- *prometheus.Desc)
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorBirthTimeFilesCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorBirthTimeFilesDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (accestime files):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // Group Cost
 
 // NewFSCollector creates an cluster of the FSCollector and instantiates
@@ -435,9 +345,9 @@ func NewInspectorGroupCostDiskCollector(opts *CollectorOpts) *InspectorGroupCost
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorGroupCostDiskCollector{
+	o := &InspectorGroupCostDiskCollector{
 		CollectorOpts: opts,
-		Cost: prometheus.NewGaugeVec(
+		Cost: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_group_cost_disk",
@@ -447,35 +357,29 @@ func NewInspectorGroupCostDiskCollector(opts *CollectorOpts) *InspectorGroupCost
 			[]string{"groupname", "price"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (group cost disk)", collect: o.collectInspectorGroupCostDiskDF}
+	return o
 }
 
-func (o *InspectorGroupCostDiskCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorGroupCostDiskCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Cost,
 	}
 }
 
-func (o *InspectorGroupCostDiskCollector) collectInspectorGroupCostDiskDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorGroupCostDiskCollector) collectInspectorGroupCostDiskDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorGroupCostDisk(context.Background(), "root")
+	mds, err := client.ListInspectorGroupCostDisk(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.Cost.Reset()
-
 	for _, m := range mds {
 
 		files, err := strconv.ParseFloat(m.Cost, 64)
 		if err == nil {
-			o.Cost.WithLabelValues(m.Groupname, m.Price).Set(files)
+			b.set(o.Cost, files, m.Groupname, m.Price)
 		}
 	}
 
@@ -491,19 +395,6 @@ func (o *InspectorGroupCostDiskCollector) Describe(ch chan<- *prometheus.Desc) {
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorGroupCostDiskCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorGroupCostDiskDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (group cost disk):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // NewFSCollector creates an cluster of the FSCollector and instantiates
 // the individual metrics that show information about the FS.
 func NewInspectorGroupCostDiskTBYearsCollector(opts *CollectorOpts) *InspectorGroupCostDiskTBYearsCollector {
@@ -511,9 +402,9 @@ func NewInspectorGroupCostDiskTBYearsCollector(opts *CollectorOpts) *InspectorGr
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &InspectorGroupCostDiskTBYearsCollector{
+	o := &InspectorGroupCostDiskTBYearsCollector{
 		CollectorOpts: opts,
-		TBYears: prometheus.NewGaugeVec(
+		TBYears: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "inspector_group_cost_disk_tbyears",
@@ -523,35 +414,29 @@ func NewInspectorGroupCostDiskTBYearsCollector(opts *CollectorOpts) *InspectorGr
 			[]string{"groupname"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "eos inspector metrics (group cost disk tbyears)", collect: o.collectInspectorGroupCostDiskTBYearsDF}
+	return o
 }
 
-func (o *InspectorGroupCostDiskTBYearsCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *InspectorGroupCostDiskTBYearsCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.TBYears,
 	}
 }
 
-func (o *InspectorGroupCostDiskTBYearsCollector) collectInspectorGroupCostDiskTBYearsDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *InspectorGroupCostDiskTBYearsCollector) collectInspectorGroupCostDiskTBYearsDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListInspectorGroupCostDiskTBYears(context.Background(), "root")
+	mds, err := client.ListInspectorGroupCostDiskTBYears(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	o.TBYears.Reset()
-
 	for _, m := range mds {
 
 		files, err := strconv.ParseFloat(m.TBYears, 64)
 		if err == nil {
-			o.TBYears.WithLabelValues(m.Groupname).Set(files)
+			b.set(o.TBYears, files, m.Groupname)
 		}
 	}
 
@@ -566,16 +451,3 @@ func (o *InspectorGroupCostDiskTBYearsCollector) Describe(ch chan<- *prometheus.
 	}
 	//ch <- o.ScrubbingStateDesc
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *InspectorGroupCostDiskTBYearsCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectInspectorGroupCostDiskTBYearsDF(); err != nil {
-		log.Println("failed collecting eos inspector metrics (group cost disk tbyears):", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
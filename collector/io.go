@@ -2,48 +2,47 @@ package collector
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/cern-eos/eos_exporter/eosclient"
 )
 
 type IOInfoCollector struct {
 	*CollectorOpts
-	Total_bwd_seeks          *prometheus.GaugeVec
-	Total_bytes_bwd_wseek    *prometheus.GaugeVec
-	Total_bytes_deleted      *prometheus.GaugeVec
-	Total_bytes_fwd_seek     *prometheus.GaugeVec
-	Total_bytes_read         *prometheus.GaugeVec
-	Total_bytes_written      *prometheus.GaugeVec
-	Total_bytes_xl_bwd_wseek *prometheus.GaugeVec
-	Total_bytes_xl_fwd_seek  *prometheus.GaugeVec
-	Total_disk_time_read     *prometheus.GaugeVec
-	Total_disk_time_write    *prometheus.GaugeVec
-	Total_files_deleted      *prometheus.GaugeVec
-	Total_fwd_seeks          *prometheus.GaugeVec
-	Total_read_calls         *prometheus.GaugeVec
-	Total_readv_calls        *prometheus.GaugeVec
-	Total_write_calls        *prometheus.GaugeVec
-	Total_xl_bwd_seeks       *prometheus.GaugeVec
-	Total_xl_fwd_seeks       *prometheus.GaugeVec
-	//Measurement *prometheus.GaugeVec
-	//Last_60s    *prometheus.GaugeVec
-	//Last_300s   *prometheus.GaugeVec
-	//Last_3600s  *prometheus.GaugeVec
-	//Last_86400s *prometheus.GaugeVec
+	batchCollector
+	Total_bwd_seeks          *metricVec
+	Total_bytes_bwd_wseek    *metricVec
+	Total_bytes_deleted      *metricVec
+	Total_bytes_fwd_seek     *metricVec
+	Total_bytes_read         *metricVec
+	Total_bytes_written      *metricVec
+	Total_bytes_xl_bwd_wseek *metricVec
+	Total_bytes_xl_fwd_seek  *metricVec
+	Total_disk_time_read     *metricVec
+	Total_disk_time_write    *metricVec
+	Total_files_deleted      *metricVec
+	Total_fwd_seeks          *metricVec
+	Total_read_calls         *metricVec
+	Total_readv_calls        *metricVec
+	Total_write_calls        *metricVec
+	Total_xl_bwd_seeks       *metricVec
+	Total_xl_fwd_seeks       *metricVec
+	//Measurement *metricVec
+	//Last_60s    *metricVec
+	//Last_300s   *metricVec
+	//Last_3600s  *metricVec
+	//Last_86400s *metricVec
 }
 
 type IOAppInfoCollector struct {
 	*CollectorOpts
-	Total_in  *prometheus.GaugeVec
-	Total_out *prometheus.GaugeVec
-	//Last_60s    *prometheus.GaugeVec
-	//Last_300s   *prometheus.GaugeVec
-	//Last_3600s  *prometheus.GaugeVec
-	//Last_86400s *prometheus.GaugeVec
+	batchCollector
+	Total_in  *metricVec
+	Total_out *metricVec
+	//Last_60s    *metricVec
+	//Last_300s   *metricVec
+	//Last_3600s  *metricVec
+	//Last_86400s *metricVec
 }
 
 // NewIOInfoCollector creates an cluster of the IOInfoCollector
@@ -52,10 +51,10 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &IOInfoCollector{
+	o := &IOInfoCollector{
 		CollectorOpts: opts,
-		Total_bwd_seeks: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bwd_seeks: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bwd_seeks_total",
 				Help:        "IO Stat Total",
@@ -63,8 +62,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_bwd_wseek: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_bwd_wseek: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_bwd_wseek_total",
 				Help:        "IO Stat Total",
@@ -72,8 +71,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_deleted: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_deleted: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_deleted_total",
 				Help:        "IO Stat Total",
@@ -81,8 +80,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_fwd_seek: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_fwd_seek: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_fwd_seek_total",
 				Help:        "IO Stat Total",
@@ -90,8 +89,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_read: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_read: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_read_total",
 				Help:        "IO Stat Total",
@@ -99,8 +98,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_written: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_written: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_written_total",
 				Help:        "IO Stat Total",
@@ -108,8 +107,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_xl_bwd_wseek: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_xl_bwd_wseek: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_xl_bwd_wseek_total",
 				Help:        "IO Stat Total",
@@ -117,8 +116,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_bytes_xl_fwd_seek: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_bytes_xl_fwd_seek: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_bytes_xl_fwd_seek_total",
 				Help:        "IO Stat Total",
@@ -126,8 +125,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_disk_time_read: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_disk_time_read: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_disk_time_read_total",
 				Help:        "IO Stat Total",
@@ -135,8 +134,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_disk_time_write: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_disk_time_write: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_disk_time_write_total",
 				Help:        "IO Stat Total",
@@ -144,8 +143,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_files_deleted: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_files_deleted: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_files_deleted_total",
 				Help:        "IO Stat Total",
@@ -153,8 +152,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_fwd_seeks: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_fwd_seeks: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_fwd_seeks_total",
 				Help:        "IO Stat Total",
@@ -162,8 +161,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_read_calls: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_read_calls: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_read_calls_total",
 				Help:        "IO Stat Total",
@@ -171,8 +170,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_readv_calls: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_readv_calls: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_readv_calls_total",
 				Help:        "IO Stat Total",
@@ -180,8 +179,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_write_calls: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_write_calls: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_write_calls_total",
 				Help:        "IO Stat Total",
@@ -189,8 +188,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_xl_bwd_seeks: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_xl_bwd_seeks: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_xl_bwd_seeks_total",
 				Help:        "IO Stat Total",
@@ -198,8 +197,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			},
 			[]string{},
 		),
-		Total_xl_fwd_seeks: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Total_xl_fwd_seeks: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "io_xl_fwd_seeks_total",
 				Help:        "IO Stat Total",
@@ -208,6 +207,8 @@ func NewIOInfoCollector(opts *CollectorOpts) *IOInfoCollector {
 			[]string{},
 		),
 	}
+	o.batchCollector = batchCollector{what: "IO info metrics", collect: o.collectIOInfoDF}
+	return o
 }
 
 // NewIOAppInfoCollector creates an cluster of the IOAppInfoCollector
@@ -216,9 +217,9 @@ func NewIOAppInfoCollector(opts *CollectorOpts) *IOAppInfoCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &IOAppInfoCollector{
+	o := &IOAppInfoCollector{
 		CollectorOpts: opts,
-		Total_in: prometheus.NewGaugeVec(
+		Total_in: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "io_app_in_bytes",
@@ -227,7 +228,7 @@ func NewIOAppInfoCollector(opts *CollectorOpts) *IOAppInfoCollector {
 			},
 			[]string{"app"},
 		),
-		Total_out: prometheus.NewGaugeVec(
+		Total_out: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "io_app_out_bytes",
@@ -237,10 +238,12 @@ func NewIOAppInfoCollector(opts *CollectorOpts) *IOAppInfoCollector {
 			[]string{"app"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "IO app info metrics", collect: o.collectIOAppInfoDF}
+	return o
 }
 
-func (o *IOInfoCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *IOInfoCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Total_bwd_seeks,
 		o.Total_bytes_bwd_wseek,
 		o.Total_bytes_deleted,
@@ -261,24 +264,17 @@ func (o *IOInfoCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *IOAppInfoCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *IOAppInfoCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Total_in,
 		o.Total_out,
 	}
 }
 
-func (o *IOInfoCollector) collectIOInfoDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		fmt.Println("Panic error while getting new eosclient: ", err)
-		panic(err)
-	}
+func (o *IOInfoCollector) collectIOInfoDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListIOInfo(context.Background())
+	mds, err := client.ListIOInfo(ctx)
 	if err != nil {
 		return err
 	}
@@ -287,97 +283,97 @@ func (o *IOInfoCollector) collectIOInfoDF() error {
 		if m.Measurement == "bwd_seeks" {
 			total_bwd_seeks, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bwd_seeks.WithLabelValues().Set(total_bwd_seeks)
+				b.set(o.Total_bwd_seeks, total_bwd_seeks)
 			}
 		}
 		if m.Measurement == "bytes_bwd_wseek" {
 			total_bytes_bwd_wseek, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_bwd_wseek.WithLabelValues().Set(total_bytes_bwd_wseek)
+				b.set(o.Total_bytes_bwd_wseek, total_bytes_bwd_wseek)
 			}
 		}
 		if m.Measurement == "bytes_deleted" {
 			total_bytes_deleted, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_deleted.WithLabelValues().Set(total_bytes_deleted)
+				b.set(o.Total_bytes_deleted, total_bytes_deleted)
 			}
 		}
 		if m.Measurement == "bytes_fwd_seek" {
 			total_bytes_fwd_seek, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_fwd_seek.WithLabelValues().Set(total_bytes_fwd_seek)
+				b.set(o.Total_bytes_fwd_seek, total_bytes_fwd_seek)
 			}
 		}
 		if m.Measurement == "bytes_read" {
 			total_bytes_read, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_read.WithLabelValues().Set(total_bytes_read)
+				b.set(o.Total_bytes_read, total_bytes_read)
 			}
 		}
 		if m.Measurement == "bytes_written" {
 			total_bytes_written, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_written.WithLabelValues().Set(total_bytes_written)
+				b.set(o.Total_bytes_written, total_bytes_written)
 			}
 		}
 		if m.Measurement == "bytes_xl_fwd_seek" {
 			total_bytes_xl_fwd_seek, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_bytes_xl_fwd_seek.WithLabelValues().Set(total_bytes_xl_fwd_seek)
+				b.set(o.Total_bytes_xl_fwd_seek, total_bytes_xl_fwd_seek)
 			}
 		}
 		if m.Measurement == "disk_time_read" {
 			total_disk_time_read, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_disk_time_read.WithLabelValues().Set(total_disk_time_read)
+				b.set(o.Total_disk_time_read, total_disk_time_read)
 			}
 		}
 		if m.Measurement == "disk_time_write" {
 			total_disk_time_write, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_disk_time_write.WithLabelValues().Set(total_disk_time_write)
+				b.set(o.Total_disk_time_write, total_disk_time_write)
 			}
 		}
 		if m.Measurement == "files_deleted" {
 			total_files_deleted, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_files_deleted.WithLabelValues().Set(total_files_deleted)
+				b.set(o.Total_files_deleted, total_files_deleted)
 			}
 		}
 		if m.Measurement == "fwd_seeks" {
 			total_fwd_seeks, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_fwd_seeks.WithLabelValues().Set(total_fwd_seeks)
+				b.set(o.Total_fwd_seeks, total_fwd_seeks)
 			}
 		}
 		if m.Measurement == "read_calls" {
 			total_read_calls, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_read_calls.WithLabelValues().Set(total_read_calls)
+				b.set(o.Total_read_calls, total_read_calls)
 			}
 		}
 		if m.Measurement == "readv_calls" {
 			total_readv_calls, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_readv_calls.WithLabelValues().Set(total_readv_calls)
+				b.set(o.Total_readv_calls, total_readv_calls)
 			}
 		}
 		if m.Measurement == "write_calls" {
 			total_write_calls, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_write_calls.WithLabelValues().Set(total_write_calls)
+				b.set(o.Total_write_calls, total_write_calls)
 			}
 		}
 		if m.Measurement == "xl_bwd_seeks" {
 			total_xl_bwd_seeks, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_xl_bwd_seeks.WithLabelValues().Set(total_xl_bwd_seeks)
+				b.set(o.Total_xl_bwd_seeks, total_xl_bwd_seeks)
 			}
 		}
 		if m.Measurement == "xl_fwd_seeks" {
 			total_xl_fwd_seeks, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_xl_fwd_seeks.WithLabelValues().Set(total_xl_fwd_seeks)
+				b.set(o.Total_xl_fwd_seeks, total_xl_fwd_seeks)
 			}
 		}
 	}
@@ -386,16 +382,10 @@ func (o *IOInfoCollector) collectIOInfoDF() error {
 
 } // collectIOInfoDF()
 
-func (o *IOAppInfoCollector) collectIOAppInfoDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *IOAppInfoCollector) collectIOAppInfoDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListIOAppInfo(context.Background())
+	mds, err := client.ListIOAppInfo(ctx)
 	if err != nil {
 		return err
 	}
@@ -405,13 +395,13 @@ func (o *IOAppInfoCollector) collectIOAppInfoDF() error {
 		if m.Measurement == "app_io_in" {
 			total_in, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_in.WithLabelValues(m.Application).Set(total_in)
+				b.set(o.Total_in, total_in, m.Application)
 			}
 		}
 		if m.Measurement == "app_io_out" {
 			total_out, err := strconv.ParseFloat(m.Total, 64)
 			if err == nil {
-				o.Total_out.WithLabelValues(m.Application).Set(total_out)
+				b.set(o.Total_out, total_out, m.Application)
 			}
 		}
 	}
@@ -427,35 +417,9 @@ func (o *IOInfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *IOInfoCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectIOInfoDF(); err != nil {
-		log.Println("failed collecting IO info metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // Describe sends the descriptors of each IOInfoCollector related metrics we have defined
 func (o *IOAppInfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range o.collectorList() {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *IOAppInfoCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectIOAppInfoDF(); err != nil {
-		log.Println("failed collecting IO info metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
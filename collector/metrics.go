@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricVec describes a metric family whose metrics are built from the eos output
+// at each collection, as const metrics, rather than kept in the collector between
+// scrapes: concurrent scrapes can not reset each other's series, and the series
+// gone from the eos output disappear.
+type metricVec struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}
+
+// newGaugeVec returns the metricVec of a gauge, e.g. a level such as the used bytes
+func newGaugeVec(opts prometheus.GaugeOpts, labelNames []string) *metricVec {
+	return newMetricVec(prometheus.Opts(opts), labelNames, prometheus.GaugeValue)
+}
+
+// newCounterVec returns the metricVec of a counter, a sum kept by EOS which only
+// grows until the MGM restarts, so that rate() works on it
+func newCounterVec(opts prometheus.CounterOpts, labelNames []string) *metricVec {
+	return newMetricVec(prometheus.Opts(opts), labelNames, prometheus.CounterValue)
+}
+
+func newMetricVec(opts prometheus.Opts, labelNames []string, valueType prometheus.ValueType) *metricVec {
+	name := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+	return &metricVec{
+		desc:      prometheus.NewDesc(name, opts.Help, labelNames, opts.ConstLabels),
+		valueType: valueType,
+	}
+}
+
+// Describe sends the descriptor of the family
+func (v *metricVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- v.desc
+}
+
+// metricBatch accumulates the metrics of one collection
+type metricBatch struct {
+	index   map[batchKey]int // position in metrics
+	metrics []prometheus.Metric
+}
+
+type batchKey struct {
+	vec *metricVec
+	lvs string
+}
+
+// set adds the metric of the label values to the batch. Setting it again replaces
+// its value, as setting a GaugeVec did, since the same series can be found on
+// several lines of the eos output.
+func (b *metricBatch) set(vec *metricVec, value float64, lvs ...string) {
+	m := prometheus.MustNewConstMetric(vec.desc, vec.valueType, value, lvs...)
+	key := batchKey{vec: vec, lvs: strings.Join(lvs, "\xff")}
+	if i, ok := b.index[key]; ok {
+		b.metrics[i] = m
+		return
+	}
+	if b.index == nil {
+		b.index = make(map[batchKey]int)
+	}
+	b.index[key] = len(b.metrics)
+	b.metrics = append(b.metrics, m)
+}
+
+// send sends the metrics of the batch to ch
+func (b *metricBatch) send(ch chan<- prometheus.Metric) {
+	for _, m := range b.metrics {
+		ch <- m
+	}
+}
+
+// batchCollector implements the Update, Collect and CollectWithContext methods of
+// the collectors building their metrics in a metricBatch
+type batchCollector struct {
+	what    string // collected, in the logged errors, e.g. "fs metrics"
+	collect func(context.Context, *metricBatch) error
+}
+
+// Update runs the eos commands and sends the resulting metrics to the provided
+// prometheus channel, nothing if they failed.
+func (c batchCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var b metricBatch
+	if err := c.collect(ctx, &b); err != nil {
+		return err
+	}
+	b.send(ch)
+	return nil
+}
+
+// Collect sends all the collected metrics to the provided prometheus channel.
+func (c batchCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectWithContext(context.Background(), ch)
+}
+
+// CollectWithContext is Collect with the eos commands bound to ctx.
+func (c batchCollector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	if err := c.Update(ctx, ch); err != nil {
+		log.Println("failed collecting "+c.what+":", err)
+	}
+}
+
+// setValue adds the metric of the label values to the batch, unless value is unknown
+func setValue[T int64 | float64](b *metricBatch, vec *metricVec, value *T, lvs ...string) {
+	if value != nil {
+		b.set(vec, float64(*value), lvs...)
+	}
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestMetricBatchKeepsLastValue(t *testing.T) {
+	reads := newCounterVec(prometheus.CounterOpts{Name: "reads_total", Help: "reads"}, []string{"app"})
+	load := newGaugeVec(prometheus.GaugeOpts{Name: "load", Help: "load"}, []string{"app"})
+
+	var b metricBatch
+	b.set(reads, 1, "a")
+	b.set(load, 0.5, "a")
+	b.set(reads, 3, "a")
+	b.set(reads, 2, "b")
+
+	ch := make(chan prometheus.Metric, len(b.metrics))
+	b.send(ch)
+	close(ch)
+
+	got := make(map[string]float64)
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		key := m.Desc().String() + pb.Label[0].GetValue()
+		if pb.Counter != nil {
+			got[key] = pb.Counter.GetValue()
+		} else {
+			got[key] = pb.Gauge.GetValue()
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 metrics, got %d", len(got))
+	}
+	if v := got[reads.desc.String()+"a"]; v != 3 {
+		t.Fatalf("expected the last value 3 of reads_total{app=a}, got %v", v)
+	}
+	if v := got[load.desc.String()+"a"]; v != 0.5 {
+		t.Fatalf("expected a gauge of 0.5, got %v", v)
+	}
+}
@@ -3,8 +3,6 @@ package collector
 import (
 	"context"
 	"fmt"
-	"log"
-	"strconv"
 
 	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,34 +28,35 @@ func humanReadableBytes(bytes float64) string {
 
 type NodeCollector struct {
 	*CollectorOpts
+	batchCollector
 	// UsedBytes displays the total used bytes in the Node
-	Host                  *prometheus.GaugeVec
-	Port                  *prometheus.GaugeVec
-	Status                *prometheus.GaugeVec
-	CfgStatus             *prometheus.GaugeVec
-	Nofs                  *prometheus.GaugeVec
-	HeartBeatDelta        *prometheus.GaugeVec
-	SumStatStatfsFree     *prometheus.GaugeVec
-	SumStatStatfsUsed     *prometheus.GaugeVec
-	SumStatStatfsTotal    *prometheus.GaugeVec
-	SumStatStatFilesFree  *prometheus.GaugeVec
-	SumStatStatFilesUsed  *prometheus.GaugeVec
-	SumStatStatFilesTotal *prometheus.GaugeVec
-	SumStatRopen          *prometheus.GaugeVec
-	SumStatWopen          *prometheus.GaugeVec
-	CfgStatSysThreads     *prometheus.GaugeVec
-	CfgStatSysVsize       *prometheus.GaugeVec
-	CfgStatSysRss         *prometheus.GaugeVec
-	CfgStatSysSockets     *prometheus.GaugeVec
-	SumStatNetInratemib   *prometheus.GaugeVec
-	SumStatNetOutratemib  *prometheus.GaugeVec
-	Info                  *prometheus.GaugeVec
+	Host                  *metricVec
+	Port                  *metricVec
+	Status                *metricVec
+	CfgStatus             *metricVec
+	Nofs                  *metricVec
+	HeartBeatDelta        *metricVec
+	SumStatStatfsFree     *metricVec
+	SumStatStatfsUsed     *metricVec
+	SumStatStatfsTotal    *metricVec
+	SumStatStatFilesFree  *metricVec
+	SumStatStatFilesUsed  *metricVec
+	SumStatStatFilesTotal *metricVec
+	SumStatRopen          *metricVec
+	SumStatWopen          *metricVec
+	CfgStatSysThreads     *metricVec
+	CfgStatSysVsize       *metricVec
+	CfgStatSysRss         *metricVec
+	CfgStatSysSockets     *metricVec
+	SumStatNetInratemib   *metricVec
+	SumStatNetOutratemib  *metricVec
+	Info                  *metricVec
 	// Info metrics for readable units
-	StatfsFreeInfo  *prometheus.GaugeVec
-	StatfsUsedInfo  *prometheus.GaugeVec
-	StatfsTotalInfo *prometheus.GaugeVec
-	VsizeInfo       *prometheus.GaugeVec
-	RssInfo         *prometheus.GaugeVec
+	StatfsFreeInfo  *metricVec
+	StatfsUsedInfo  *metricVec
+	StatfsTotalInfo *metricVec
+	VsizeInfo       *metricVec
+	RssInfo         *metricVec
 }
 
 /*
@@ -108,9 +107,9 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 
-	return &NodeCollector{
+	o := &NodeCollector{
 		CollectorOpts: opts,
-		Status: prometheus.NewGaugeVec(
+		Status: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_status",
@@ -119,7 +118,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		CfgStatus: prometheus.NewGaugeVec(
+		CfgStatus: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_cfgstatus",
@@ -128,7 +127,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		HeartBeatDelta: prometheus.NewGaugeVec(
+		HeartBeatDelta: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_heartbeatdelta_seconds",
@@ -137,7 +136,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		Nofs: prometheus.NewGaugeVec(
+		Nofs: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_nofs",
@@ -146,7 +145,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatfsFree: prometheus.NewGaugeVec(
+		SumStatStatfsFree: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_freebytes",
@@ -155,7 +154,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatfsUsed: prometheus.NewGaugeVec(
+		SumStatStatfsUsed: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_usedbytes",
@@ -164,7 +163,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatfsTotal: prometheus.NewGaugeVec(
+		SumStatStatfsTotal: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_sizebytes",
@@ -173,7 +172,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatFilesFree: prometheus.NewGaugeVec(
+		SumStatStatFilesFree: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_freefiles",
@@ -182,7 +181,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatFilesUsed: prometheus.NewGaugeVec(
+		SumStatStatFilesUsed: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_usedfiles",
@@ -191,7 +190,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatStatFilesTotal: prometheus.NewGaugeVec(
+		SumStatStatFilesTotal: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_totalfiles",
@@ -200,7 +199,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatRopen: prometheus.NewGaugeVec(
+		SumStatRopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_disk_ropen",
@@ -209,7 +208,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatWopen: prometheus.NewGaugeVec(
+		SumStatWopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_disk_wopen",
@@ -218,7 +217,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		CfgStatSysThreads: prometheus.NewGaugeVec(
+		CfgStatSysThreads: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_threads",
@@ -227,7 +226,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		CfgStatSysVsize: prometheus.NewGaugeVec(
+		CfgStatSysVsize: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_vsize",
@@ -236,7 +235,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		CfgStatSysRss: prometheus.NewGaugeVec(
+		CfgStatSysRss: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_rss",
@@ -245,7 +244,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		CfgStatSysSockets: prometheus.NewGaugeVec(
+		CfgStatSysSockets: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_sockets",
@@ -254,7 +253,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatNetInratemib: prometheus.NewGaugeVec(
+		SumStatNetInratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_net_inratemib",
@@ -263,7 +262,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		SumStatNetOutratemib: prometheus.NewGaugeVec(
+		SumStatNetOutratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_net_outratemib",
@@ -272,7 +271,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port"},
 		),
-		Info: prometheus.NewGaugeVec(
+		Info: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_info",
@@ -281,7 +280,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port", "eos_version", "xrootd_version", "kernel", "geotag"},
 		),
-		StatfsFreeInfo: prometheus.NewGaugeVec(
+		StatfsFreeInfo: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_freebytes_info",
@@ -290,7 +289,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port", "geotag", "human_readable"},
 		),
-		StatfsUsedInfo: prometheus.NewGaugeVec(
+		StatfsUsedInfo: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_usedbytes_info",
@@ -299,7 +298,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port", "geotag", "human_readable"},
 		),
-		StatfsTotalInfo: prometheus.NewGaugeVec(
+		StatfsTotalInfo: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_statfs_sizebytes_info",
@@ -308,7 +307,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port", "geotag", "human_readable"},
 		),
-		VsizeInfo: prometheus.NewGaugeVec(
+		VsizeInfo: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_vsize_info",
@@ -317,7 +316,7 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			},
 			[]string{"node", "port", "geotag", "human_readable"},
 		),
-		RssInfo: prometheus.NewGaugeVec(
+		RssInfo: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "node_rss_info",
@@ -327,10 +326,12 @@ func NewNodeCollector(opts *CollectorOpts) *NodeCollector {
 			[]string{"node", "port", "geotag", "human_readable"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "node metrics", collect: o.collectNodeDF}
+	return o
 }
 
-func (o *NodeCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *NodeCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Status,
 		o.CfgStatus,
 		o.Nofs,
@@ -358,173 +359,88 @@ func (o *NodeCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *NodeCollector) collectNodeDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *NodeCollector) collectNodeDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListNode(context.Background(), "root")
+	mds, err := client.ListNode(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	// Reset gauge metrics to remove metrics of removed nodes
-
-	o.Status.Reset()
-	o.CfgStatus.Reset()
-	o.Nofs.Reset()
-	o.HeartBeatDelta.Reset()
-	o.SumStatStatfsFree.Reset()
-	o.SumStatStatfsUsed.Reset()
-	o.SumStatStatfsTotal.Reset()
-	o.SumStatStatFilesFree.Reset()
-	o.SumStatStatFilesUsed.Reset()
-	o.SumStatStatFilesTotal.Reset()
-	o.SumStatRopen.Reset()
-	o.SumStatWopen.Reset()
-	o.CfgStatSysThreads.Reset()
-	o.CfgStatSysVsize.Reset()
-	o.CfgStatSysRss.Reset()
-	o.CfgStatSysSockets.Reset()
-	o.SumStatNetInratemib.Reset()
-	o.SumStatNetOutratemib.Reset()
-	o.Info.Reset()
-	o.StatfsFreeInfo.Reset()
-	o.StatfsUsedInfo.Reset()
-	o.StatfsTotalInfo.Reset()
-	o.VsizeInfo.Reset()
-	o.RssInfo.Reset()
-
 	for _, m := range mds {
 
 		// Status: 1: online, 0: offline
 
 		var status int
 
-		switch stat := m.Status; stat {
-		case "online":
+		switch m.Status {
+		case eosclient.Online:
 			status = 1
-		case "offline":
+		case eosclient.Offline:
 			status = 0
 		}
 
-		o.Status.WithLabelValues(m.Host, m.Port).Set(float64(status))
+		b.set(o.Status, float64(status), m.Host, m.Port)
 
 		// Config status: 1: on, 0: off
 
-		var cfg_status int
+		b.set(o.CfgStatus, boolValue(m.CfgStatus), m.Host, m.Port)
 
-		switch stat := m.CfgStatus; stat {
-		case "on":
-			cfg_status = 1
-		case "off":
-			cfg_status = 0
-		}
+		setValue(b, o.HeartBeatDelta, m.HeartBeatDelta, m.Host, m.Port)
 
-		o.CfgStatus.WithLabelValues(m.Host, m.Port).Set(float64(cfg_status))
+		setValue(b, o.Nofs, m.Nofs, m.Host, m.Port)
 
-		heartbeatdelta, err := strconv.ParseFloat(m.HeartBeatDelta, 64)
-		if err == nil {
-			o.HeartBeatDelta.WithLabelValues(m.Host, m.Port).Set(heartbeatdelta)
-		}
+		setValue(b, o.SumStatStatfsFree, m.SumStatStatfsFree, m.Host, m.Port)
 
-		nofs, err := strconv.ParseFloat(m.Nofs, 64)
-		if err == nil {
-			o.Nofs.WithLabelValues(m.Host, m.Port).Set(nofs)
-		}
+		setValue(b, o.SumStatStatfsUsed, m.SumStatStatfsUsed, m.Host, m.Port)
 
-		fbytes, err := strconv.ParseFloat(m.SumStatStatfsFree, 64)
-		if err == nil {
-			o.SumStatStatfsFree.WithLabelValues(m.Host, m.Port).Set(fbytes)
-		}
+		setValue(b, o.SumStatStatfsTotal, m.SumStatStatfsTotal, m.Host, m.Port)
 
-		ubytes, err := strconv.ParseFloat(m.SumStatStatfsUsed, 64)
-		if err == nil {
-			o.SumStatStatfsUsed.WithLabelValues(m.Host, m.Port).Set(ubytes)
-		}
+		setValue(b, o.SumStatStatFilesFree, m.SumStatStatFilesFree, m.Host, m.Port)
 
-		tbytes, err := strconv.ParseFloat(m.SumStatStatfsTotal, 64)
-		if err == nil {
-			o.SumStatStatfsTotal.WithLabelValues(m.Host, m.Port).Set(tbytes)
-		}
+		setValue(b, o.SumStatStatFilesUsed, m.SumStatStatFilesUsed, m.Host, m.Port)
 
-		ffiles, err := strconv.ParseFloat(m.SumStatStatFilesFree, 64)
-		if err == nil {
-			o.SumStatStatFilesFree.WithLabelValues(m.Host, m.Port).Set(ffiles)
-		}
+		setValue(b, o.SumStatStatFilesTotal, m.SumStatStatFilesTotal, m.Host, m.Port)
 
-		ufiles, err := strconv.ParseFloat(m.SumStatStatFilesUsed, 64)
-		if err == nil {
-			o.SumStatStatFilesUsed.WithLabelValues(m.Host, m.Port).Set(ufiles)
-		}
+		setValue(b, o.SumStatRopen, m.SumStatRopen, m.Host, m.Port)
 
-		tfiles, err := strconv.ParseFloat(m.SumStatStatFilesTotal, 64)
-		if err == nil {
-			o.SumStatStatFilesTotal.WithLabelValues(m.Host, m.Port).Set(tfiles)
-		}
+		setValue(b, o.SumStatWopen, m.SumStatWopen, m.Host, m.Port)
 
-		ropen, err := strconv.ParseFloat(m.SumStatRopen, 64)
-		if err == nil {
-			o.SumStatRopen.WithLabelValues(m.Host, m.Port).Set(ropen)
-		}
+		setValue(b, o.SumStatNetInratemib, m.SumStatNetInratemib, m.Host, m.Port)
 
-		wopen, err := strconv.ParseFloat(m.SumStatWopen, 64)
-		if err == nil {
-			o.SumStatWopen.WithLabelValues(m.Host, m.Port).Set(wopen)
-		}
+		setValue(b, o.SumStatNetOutratemib, m.SumStatNetOutratemib, m.Host, m.Port)
 
-		netin, err := strconv.ParseFloat(m.SumStatNetInratemib, 64)
-		if err == nil {
-			o.SumStatNetInratemib.WithLabelValues(m.Host, m.Port).Set(netin)
-		}
+		setValue(b, o.CfgStatSysThreads, m.CfgStatSysThreads, m.Host, m.Port)
 
-		netout, err := strconv.ParseFloat(m.SumStatNetOutratemib, 64)
-		if err == nil {
-			o.SumStatNetOutratemib.WithLabelValues(m.Host, m.Port).Set(netout)
-		}
-
-		threads, err := strconv.ParseFloat(m.CfgStatSysThreads, 64)
-		if err == nil {
-			o.CfgStatSysThreads.WithLabelValues(m.Host, m.Port).Set(threads)
-		}
+		setValue(b, o.CfgStatSysVsize, m.CfgStatSysVsize, m.Host, m.Port)
 
-		vsize, err := strconv.ParseFloat(m.CfgStatSysVsize, 64)
-		if err == nil {
-			o.CfgStatSysVsize.WithLabelValues(m.Host, m.Port).Set(vsize)
-		}
+		setValue(b, o.CfgStatSysRss, m.CfgStatSysRss, m.Host, m.Port)
 
-		rss, err := strconv.ParseFloat(m.CfgStatSysRss, 64)
-		if err == nil {
-			o.CfgStatSysRss.WithLabelValues(m.Host, m.Port).Set(rss)
-		}
-
-		sockets, err := strconv.ParseFloat(m.CfgStatSysSockets, 64)
-		if err == nil {
-			o.CfgStatSysSockets.WithLabelValues(m.Host, m.Port).Set(sockets)
-		}
+		setValue(b, o.CfgStatSysSockets, m.CfgStatSysSockets, m.Host, m.Port)
 
 		// We send just a dummy 1 as value for the eos_node_info metric, and metadata on labels
-		o.Info.WithLabelValues(m.Host, m.Port, m.EOSVersion, m.XRootDVersion, m.Kernel, m.Geotag).Set(1)
+		b.set(o.Info, 1, m.Host, m.Port, m.EOSVersion, m.XRootDVersion, m.Kernel, m.Geotag)
 
 		// Add readable byte info metrics
-		if fbytes, err := strconv.ParseFloat(m.SumStatStatfsFree, 64); err == nil {
-			o.StatfsFreeInfo.WithLabelValues(m.Host, m.Port, m.Geotag, humanReadableBytes(fbytes)).Set(fbytes)
+		if m.SumStatStatfsFree != nil {
+			fbytes := float64(*m.SumStatStatfsFree)
+			b.set(o.StatfsFreeInfo, fbytes, m.Host, m.Port, m.Geotag, humanReadableBytes(fbytes))
 		}
-		if ubytes, err := strconv.ParseFloat(m.SumStatStatfsUsed, 64); err == nil {
-			o.StatfsUsedInfo.WithLabelValues(m.Host, m.Port, m.Geotag, humanReadableBytes(ubytes)).Set(ubytes)
+		if m.SumStatStatfsUsed != nil {
+			ubytes := float64(*m.SumStatStatfsUsed)
+			b.set(o.StatfsUsedInfo, ubytes, m.Host, m.Port, m.Geotag, humanReadableBytes(ubytes))
 		}
-		if tbytes, err := strconv.ParseFloat(m.SumStatStatfsTotal, 64); err == nil {
-			o.StatfsTotalInfo.WithLabelValues(m.Host, m.Port, m.Geotag, humanReadableBytes(tbytes)).Set(tbytes)
+		if m.SumStatStatfsTotal != nil {
+			tbytes := float64(*m.SumStatStatfsTotal)
+			b.set(o.StatfsTotalInfo, tbytes, m.Host, m.Port, m.Geotag, humanReadableBytes(tbytes))
 		}
-		if vsize, err := strconv.ParseFloat(m.CfgStatSysVsize, 64); err == nil {
-			o.VsizeInfo.WithLabelValues(m.Host, m.Port, m.Geotag, humanReadableBytes(vsize)).Set(vsize)
+		if m.CfgStatSysVsize != nil {
+			vsize := float64(*m.CfgStatSysVsize)
+			b.set(o.VsizeInfo, vsize, m.Host, m.Port, m.Geotag, humanReadableBytes(vsize))
 		}
-		if rss, err := strconv.ParseFloat(m.CfgStatSysRss, 64); err == nil {
-			o.RssInfo.WithLabelValues(m.Host, m.Port, m.Geotag, humanReadableBytes(rss)).Set(rss)
+		if m.CfgStatSysRss != nil {
+			rss := float64(*m.CfgStatSysRss)
+			b.set(o.RssInfo, rss, m.Host, m.Port, m.Geotag, humanReadableBytes(rss))
 		}
 	}
 
@@ -538,16 +454,3 @@ func (o *NodeCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *NodeCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectNodeDF(); err != nil {
-		log.Println("failed collecting node metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
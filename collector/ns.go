@@ -2,97 +2,83 @@ package collector
 
 import (
 	"context"
-	"log"
 	"strconv"
 
 	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
-
 	//"os"
 	//"bufio"
-	"fmt"
 	//"strings"
 )
 
 type NSCollector struct {
 	*CollectorOpts
-	Boot_file_time                             *prometheus.GaugeVec
-	Boot_status                                *prometheus.GaugeVec
-	Boot_time                                  *prometheus.GaugeVec
-	Cache_container_maxsize                    *prometheus.GaugeVec
-	Cache_container_occupancy                  *prometheus.GaugeVec
-	Cache_files_maxsize                        *prometheus.GaugeVec
-	Cache_files_occupancy                      *prometheus.GaugeVec
-	Fds_all                                    *prometheus.GaugeVec
-	Fusex_activeclients                        *prometheus.GaugeVec
-	Fusex_caps                                 *prometheus.GaugeVec
-	Fusex_clients                              *prometheus.GaugeVec
-	Fusex_lockedclients                        *prometheus.GaugeVec
-	Hanging_since                              *prometheus.GaugeVec
-	Latency_dirs                               *prometheus.GaugeVec
-	Latency_files                              *prometheus.GaugeVec
-	Latency_pending_updates                    *prometheus.GaugeVec
-	Latencypeak_eosviewmutex_1min              *prometheus.GaugeVec
-	Latencypeak_eosviewmutex_2min              *prometheus.GaugeVec
-	Latencypeak_eosviewmutex_5min              *prometheus.GaugeVec
-	Latencypeak_eosviewmutex_last              *prometheus.GaugeVec
-	Qclient_rtt_ms_min                         *prometheus.GaugeVec
-	Qclient_rtt_ms_avg                         *prometheus.GaugeVec
-	Qclient_rtt_ms_max                         *prometheus.GaugeVec
-	Qclient_rtt_ms_peak_1min                   *prometheus.GaugeVec
-	Qclient_rtt_ms_peak_2min                   *prometheus.GaugeVec
-	Qclient_rtt_ms_peak_5min                   *prometheus.GaugeVec
-	Memory_growth                              *prometheus.GaugeVec
-	Memory_resident                            *prometheus.GaugeVec
-	Memory_share                               *prometheus.GaugeVec
-	Memory_virtual                             *prometheus.GaugeVec
-	Stat_threads                               *prometheus.GaugeVec
-	Total_directories                          *prometheus.GaugeVec
-	Total_directories_changelog_avg_entry_size *prometheus.GaugeVec
-	Total_directories_changelog_size           *prometheus.GaugeVec
-	Total_files                                *prometheus.GaugeVec
-	Total_files_changelog_avg_entry_size       *prometheus.GaugeVec
-	Total_files_changelog_size                 *prometheus.GaugeVec
-	Uptime                                     *prometheus.GaugeVec
-	Cache_files_requests                       *prometheus.GaugeVec
-	Cache_files_hits                           *prometheus.GaugeVec
-	Cache_containers_requests                  *prometheus.GaugeVec
-	Cache_containers_hits                      *prometheus.GaugeVec
-	Traffic_shaping_enabled                    *prometheus.GaugeVec
+	batchCollector
+	Boot_file_time                             *metricVec
+	Boot_status                                *metricVec
+	Boot_time                                  *metricVec
+	Cache_container_maxsize                    *metricVec
+	Cache_container_occupancy                  *metricVec
+	Cache_files_maxsize                        *metricVec
+	Cache_files_occupancy                      *metricVec
+	Fds_all                                    *metricVec
+	Fusex_activeclients                        *metricVec
+	Fusex_caps                                 *metricVec
+	Fusex_clients                              *metricVec
+	Fusex_lockedclients                        *metricVec
+	Hanging_since                              *metricVec
+	Latency_dirs                               *metricVec
+	Latency_files                              *metricVec
+	Latency_pending_updates                    *metricVec
+	Latencypeak_eosviewmutex_1min              *metricVec
+	Latencypeak_eosviewmutex_2min              *metricVec
+	Latencypeak_eosviewmutex_5min              *metricVec
+	Latencypeak_eosviewmutex_last              *metricVec
+	Qclient_rtt_ms_min                         *metricVec
+	Qclient_rtt_ms_avg                         *metricVec
+	Qclient_rtt_ms_max                         *metricVec
+	Qclient_rtt_ms_peak_1min                   *metricVec
+	Qclient_rtt_ms_peak_2min                   *metricVec
+	Qclient_rtt_ms_peak_5min                   *metricVec
+	Memory_growth                              *metricVec
+	Memory_resident                            *metricVec
+	Memory_share                               *metricVec
+	Memory_virtual                             *metricVec
+	Stat_threads                               *metricVec
+	Total_directories                          *metricVec
+	Total_directories_changelog_avg_entry_size *metricVec
+	Total_directories_changelog_size           *metricVec
+	Total_files                                *metricVec
+	Total_files_changelog_avg_entry_size       *metricVec
+	Total_files_changelog_size                 *metricVec
+	Uptime                                     *metricVec
+	Cache_files_requests                       *metricVec
+	Cache_files_hits                           *metricVec
+	Cache_containers_requests                  *metricVec
+	Cache_containers_hits                      *metricVec
+	Traffic_shaping_enabled                    *metricVec
 }
 
 type NSActivityCollector struct {
 	*CollectorOpts
-	Sum        *prometheus.GaugeVec
-	Last_5s    *prometheus.GaugeVec
-	Last_60s   *prometheus.GaugeVec
-	Last_300s  *prometheus.GaugeVec
-	Last_3600s *prometheus.GaugeVec
+	batchCollector
+	Sum        *metricVec
+	Last_5s    *metricVec
+	Last_60s   *metricVec
+	Last_300s  *metricVec
+	Last_3600s *metricVec
 }
 
 type NSBatchCollector struct {
 	*CollectorOpts
-	Sum        *prometheus.GaugeVec
-	Last_5s    *prometheus.GaugeVec
-	Last_60s   *prometheus.GaugeVec
-	Last_300s  *prometheus.GaugeVec
-	Last_3600s *prometheus.GaugeVec
+	batchCollector
+	Sum        *metricVec
+	Last_5s    *metricVec
+	Last_60s   *metricVec
+	Last_300s  *metricVec
+	Last_3600s *metricVec
 }
 
-var Mds []*eosclient.NSInfo
-var Mdsact []*eosclient.NSActivityInfo
-var Mdsbatch []*eosclient.NSBatchInfo
-var err error
-
-/*func init() {
-	Mds, Mdsact, Mdsbatch, err = getNSData()
-	if err == nil {
-		fmt.Println("NS Data initialized")
-	} else {
-		panic(err)
-	}
-}*/
-
 // NewNSCollector creates an instance of the NSCollector and instantiates
 // the individual metrics that show information about the NS.
 func NewNSCollector(opts *CollectorOpts) *NSCollector {
@@ -100,9 +86,9 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &NSCollector{
+	o := &NSCollector{
 		CollectorOpts: opts,
-		Boot_file_time: prometheus.NewGaugeVec(
+		Boot_file_time: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_boot_file_time_seconds",
@@ -111,7 +97,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		//Boot_status: prometheus.NewGaugeVec(
+		//Boot_status: newGaugeVec(
 		//	prometheus.GaugeOpts{
 		//		Namespace:   namespace,
 		//		Name:        "ns_boot_status",
@@ -120,7 +106,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 		//	},
 		//	[]string{},
 		//),
-		Boot_time: prometheus.NewGaugeVec(
+		Boot_time: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_boot_time_seconds",
@@ -129,7 +115,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_container_maxsize: prometheus.NewGaugeVec(
+		Cache_container_maxsize: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_container_max_total",
@@ -138,7 +124,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_container_occupancy: prometheus.NewGaugeVec(
+		Cache_container_occupancy: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_container_occ_total",
@@ -147,7 +133,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_files_maxsize: prometheus.NewGaugeVec(
+		Cache_files_maxsize: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_files_total",
@@ -156,7 +142,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_files_occupancy: prometheus.NewGaugeVec(
+		Cache_files_occupancy: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_files_occ_total",
@@ -165,7 +151,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Fds_all: prometheus.NewGaugeVec(
+		Fds_all: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_fds_total",
@@ -174,7 +160,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Fusex_activeclients: prometheus.NewGaugeVec(
+		Fusex_activeclients: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_fusex_activeclients_total",
@@ -183,7 +169,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Fusex_caps: prometheus.NewGaugeVec(
+		Fusex_caps: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_fusex_caps_total",
@@ -192,7 +178,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Fusex_clients: prometheus.NewGaugeVec(
+		Fusex_clients: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_fusex_clients_total",
@@ -201,7 +187,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Fusex_lockedclients: prometheus.NewGaugeVec(
+		Fusex_lockedclients: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_fusex_locked_clients_total",
@@ -210,7 +196,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latency_dirs: prometheus.NewGaugeVec(
+		Latency_dirs: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_dirs_seconds",
@@ -219,7 +205,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latency_files: prometheus.NewGaugeVec(
+		Latency_files: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_files_seconds",
@@ -228,7 +214,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latency_pending_updates: prometheus.NewGaugeVec(
+		Latency_pending_updates: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_pend_upd_seconds",
@@ -237,7 +223,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latencypeak_eosviewmutex_1min: prometheus.NewGaugeVec(
+		Latencypeak_eosviewmutex_1min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_eosvm_1min_seconds",
@@ -246,7 +232,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latencypeak_eosviewmutex_2min: prometheus.NewGaugeVec(
+		Latencypeak_eosviewmutex_2min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_eosvm_2min_seconds",
@@ -255,7 +241,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latencypeak_eosviewmutex_5min: prometheus.NewGaugeVec(
+		Latencypeak_eosviewmutex_5min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_eosvm_5min_seconds",
@@ -264,7 +250,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Latencypeak_eosviewmutex_last: prometheus.NewGaugeVec(
+		Latencypeak_eosviewmutex_last: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_lat_eosvm_last_seconds",
@@ -273,7 +259,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_min: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_min_milliseconds",
@@ -282,7 +268,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_avg: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_avg: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_avg_milliseconds",
@@ -291,7 +277,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_max: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_max: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_max_milliseconds",
@@ -300,7 +286,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_peak_1min: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_peak_1min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_peak_1min_milliseconds",
@@ -309,7 +295,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_peak_2min: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_peak_2min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_peak_2min_milliseconds",
@@ -318,7 +304,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Qclient_rtt_ms_peak_5min: prometheus.NewGaugeVec(
+		Qclient_rtt_ms_peak_5min: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_qclient_rtt_peak_5min_milliseconds",
@@ -327,7 +313,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Memory_growth: prometheus.NewGaugeVec(
+		Memory_growth: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_mem_growth_bytes",
@@ -336,7 +322,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Memory_resident: prometheus.NewGaugeVec(
+		Memory_resident: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_mem_res_bytes",
@@ -345,7 +331,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Memory_share: prometheus.NewGaugeVec(
+		Memory_share: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_mem_share_bytes",
@@ -354,7 +340,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Memory_virtual: prometheus.NewGaugeVec(
+		Memory_virtual: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_mem_virt_bytes",
@@ -363,7 +349,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Stat_threads: prometheus.NewGaugeVec(
+		Stat_threads: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_threads_total",
@@ -372,7 +358,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_directories: prometheus.NewGaugeVec(
+		Total_directories: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_dirs_total",
@@ -381,7 +367,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_directories_changelog_avg_entry_size: prometheus.NewGaugeVec(
+		Total_directories_changelog_avg_entry_size: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_dirs_clog_avg_entry_size_total",
@@ -390,7 +376,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_directories_changelog_size: prometheus.NewGaugeVec(
+		Total_directories_changelog_size: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_dirs_clog_size_total",
@@ -399,7 +385,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_files: prometheus.NewGaugeVec(
+		Total_files: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_files_total",
@@ -408,7 +394,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_files_changelog_avg_entry_size: prometheus.NewGaugeVec(
+		Total_files_changelog_avg_entry_size: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_files_clog_avg_entry_size_total",
@@ -417,7 +403,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Total_files_changelog_size: prometheus.NewGaugeVec(
+		Total_files_changelog_size: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_files_clog_size_total",
@@ -426,7 +412,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Uptime: prometheus.NewGaugeVec(
+		Uptime: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_uptime_seconds",
@@ -435,8 +421,8 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_files_requests: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Cache_files_requests: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_files_requests_total",
 				Help:        "Cache_files_requests: Number of cache file requests.",
@@ -444,8 +430,8 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_files_hits: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Cache_files_hits: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_files_hits_total",
 				Help:        "Cache_files_hits: Number of cache file hits.",
@@ -453,8 +439,8 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_containers_requests: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Cache_containers_requests: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_container_requests_total",
 				Help:        "Cache_container_requests: Number of cache container requests.",
@@ -462,8 +448,8 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Cache_containers_hits: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Cache_containers_hits: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_cache_container_hits_total",
 				Help:        "Cache_container_hits: Number of cache container hits.",
@@ -471,7 +457,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Traffic_shaping_enabled: prometheus.NewGaugeVec(
+		Traffic_shaping_enabled: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_traffic_shaping_enabled",
@@ -480,7 +466,7 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			},
 			[]string{},
 		),
-		Hanging_since: prometheus.NewGaugeVec(
+		Hanging_since: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_hanging_since_seconds",
@@ -490,6 +476,8 @@ func NewNSCollector(opts *CollectorOpts) *NSCollector {
 			[]string{},
 		),
 	}
+	o.batchCollector = batchCollector{what: "ns metrics", collect: o.collectNSDF}
+	return o
 }
 
 // NewNSActivityCollector creates an instance of the NSActivityCollector and instantiates
@@ -499,10 +487,10 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &NSActivityCollector{
+	o := &NSActivityCollector{
 		CollectorOpts: opts,
-		Sum: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Sum: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_stat_sum_total",
 				Help:        "Sum: Cummulated ocurrences of the operation.",
@@ -510,7 +498,7 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 			},
 			[]string{"user", "operation"},
 		),
-		Last_5s: prometheus.NewGaugeVec(
+		Last_5s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_stat_last5s",
@@ -519,7 +507,7 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 			},
 			[]string{"user", "operation"},
 		),
-		Last_60s: prometheus.NewGaugeVec(
+		Last_60s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_stat_last1min",
@@ -528,7 +516,7 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 			},
 			[]string{"user", "operation"},
 		),
-		Last_300s: prometheus.NewGaugeVec(
+		Last_300s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_stat_last5min",
@@ -537,7 +525,7 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 			},
 			[]string{"user", "operation"},
 		),
-		Last_3600s: prometheus.NewGaugeVec(
+		Last_3600s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_stat_last1h",
@@ -547,6 +535,8 @@ func NewNSActivityCollector(opts *CollectorOpts) *NSActivityCollector {
 			[]string{"user", "operation"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "ns_activity metrics", collect: o.collectNSActivityDF}
+	return o
 }
 
 // NewNSBatchCollector creates an instance of the NSBatchCollector and instantiates
@@ -556,10 +546,10 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &NSBatchCollector{
+	o := &NSBatchCollector{
 		CollectorOpts: opts,
-		Sum: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
+		Sum: newCounterVec(
+			prometheus.CounterOpts{
 				Namespace:   namespace,
 				Name:        "ns_batch_sum_total",
 				Help:        "Sum: Cummulated ocurrences of the overloading operation.",
@@ -567,7 +557,7 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 			},
 			[]string{"user", "operation", "impact_level"},
 		),
-		Last_5s: prometheus.NewGaugeVec(
+		Last_5s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_batch_last5s",
@@ -576,7 +566,7 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 			},
 			[]string{"user", "operation", "impact_level"},
 		),
-		Last_60s: prometheus.NewGaugeVec(
+		Last_60s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_batch_last1min",
@@ -585,7 +575,7 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 			},
 			[]string{"user", "operation", "impact_level"},
 		),
-		Last_300s: prometheus.NewGaugeVec(
+		Last_300s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_batch_last5min",
@@ -594,7 +584,7 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 			},
 			[]string{"user", "operation", "impact_level"},
 		),
-		Last_3600s: prometheus.NewGaugeVec(
+		Last_3600s: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "ns_batch_last1h",
@@ -604,10 +594,12 @@ func NewNSBatchCollector(opts *CollectorOpts) *NSBatchCollector {
 			[]string{"user", "operation", "impact_level"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "ns_batch metrics", collect: o.collectNSBatchDF}
+	return o
 }
 
-func (o *NSCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *NSCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Boot_file_time,
 		//o.Boot_status,
 		o.Boot_time,
@@ -620,6 +612,7 @@ func (o *NSCollector) collectorList() []prometheus.Collector {
 		o.Fusex_caps,
 		o.Fusex_clients,
 		o.Fusex_lockedclients,
+		o.Hanging_since,
 		o.Latency_dirs,
 		o.Latency_files,
 		o.Latency_pending_updates,
@@ -653,8 +646,8 @@ func (o *NSCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *NSActivityCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *NSActivityCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Sum,
 		o.Last_5s,
 		o.Last_60s,
@@ -663,8 +656,8 @@ func (o *NSActivityCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *NSBatchCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *NSBatchCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Sum,
 		o.Last_5s,
 		o.Last_60s,
@@ -673,17 +666,12 @@ func (o *NSBatchCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func getNSData(o *CollectorOpts) ([]*eosclient.NSInfo, []*eosclient.NSActivityInfo, []*eosclient.NSBatchInfo, error) {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		fmt.Println("Panic error when creating eosclient in getNSData")
-		panic(err)
-	}
+// getNSData runs the ns stat and who commands shared by the ns, ns_activity and ns_batch collectors.
+// When the collectors share a Snapshot executor the commands only run once per scrape.
+func getNSData(ctx context.Context, o *CollectorOpts) ([]*eosclient.NSInfo, []*eosclient.NSActivityInfo, []*eosclient.NSBatchInfo, error) {
+	client := o.Client
 
-	mds, mdsact, mdsbatch, err := client.ListNS(context.Background())
+	mds, mdsact, mdsbatch, err := client.ListNS(ctx)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -692,22 +680,19 @@ func getNSData(o *CollectorOpts) ([]*eosclient.NSInfo, []*eosclient.NSActivityIn
 
 }
 
-func (o *NSCollector) collectNSDF() error {
+func (o *NSCollector) collectNSDF(ctx context.Context, b *metricBatch) error {
 
-	Mds, Mdsact, Mdsbatch, err = getNSData(o.CollectorOpts)
+	mds, _, _, err := getNSData(ctx, o.CollectorOpts)
 	if err != nil {
 		return err
 	}
 
 	//var boot_status float64
-	for _, m := range Mds {
+	for _, m := range mds {
 
 		// Boot_file_time
 
-		boot_ft, err := strconv.ParseFloat(m.Boot_file_time, 64)
-		if err == nil {
-			o.Boot_file_time.WithLabelValues().Set(boot_ft)
-		}
+		setValue(b, o.Boot_file_time, m.Boot_file_time)
 
 		//// Boot_status
 
@@ -718,288 +703,164 @@ func (o *NSCollector) collectNSDF() error {
 		//		boot_status = 0
 		//}
 
-		//o.Boot_status.WithLabelValues().Set(float64(boot_status))
+		//b.set(o.Boot_status, float64(boot_status))
 
 		// Boot_time
 
-		boot_time, err := strconv.ParseFloat(m.Boot_time, 64)
-		if err == nil {
-			o.Boot_time.WithLabelValues().Set(boot_time)
-		}
+		setValue(b, o.Boot_time, m.Boot_time)
 
 		// Cache_container_maxsize
 
-		cache_cont_max, err := strconv.ParseFloat(m.Cache_container_maxsize, 64)
-		if err == nil {
-			o.Cache_container_maxsize.WithLabelValues().Set(cache_cont_max)
-		}
+		setValue(b, o.Cache_container_maxsize, m.Cache_container_maxsize)
 
 		// Cache_container_occupancy
 
-		cache_cont_occ, err := strconv.ParseFloat(m.Cache_container_occupancy, 64)
-		if err == nil {
-			o.Cache_container_occupancy.WithLabelValues().Set(cache_cont_occ)
-		}
+		setValue(b, o.Cache_container_occupancy, m.Cache_container_occupancy)
 
 		// Cache_files_maxsize
 
-		cache_files_max, err := strconv.ParseFloat(m.Cache_files_maxsize, 64)
-		if err == nil {
-			o.Cache_files_maxsize.WithLabelValues().Set(cache_files_max)
-		}
+		setValue(b, o.Cache_files_maxsize, m.Cache_files_maxsize)
 
 		// Cache_files_occupancy
 
-		cache_files_occ, err := strconv.ParseFloat(m.Cache_files_occupancy, 64)
-		if err == nil {
-			o.Cache_files_occupancy.WithLabelValues().Set(cache_files_occ)
-		}
+		setValue(b, o.Cache_files_occupancy, m.Cache_files_occupancy)
 
 		// Fds_all
 
-		fds_all, err := strconv.ParseFloat(m.Fds_all, 64)
-		if err == nil {
-			o.Fds_all.WithLabelValues().Set(fds_all)
-		}
+		setValue(b, o.Fds_all, m.Fds_all)
 
 		// Fusex_activeclients
 
-		fusex_actclients, err := strconv.ParseFloat(m.Fusex_activeclients, 64)
-		if err == nil {
-			o.Fusex_activeclients.WithLabelValues().Set(fusex_actclients)
-		}
+		setValue(b, o.Fusex_activeclients, m.Fusex_activeclients)
 
 		// Fusex_caps
 
-		fusex_caps, err := strconv.ParseFloat(m.Fusex_caps, 64)
-		if err == nil {
-			o.Fusex_caps.WithLabelValues().Set(fusex_caps)
-		}
+		setValue(b, o.Fusex_caps, m.Fusex_caps)
 
 		// Fusex_clients
 
-		fusex_clients, err := strconv.ParseFloat(m.Fusex_clients, 64)
-		if err == nil {
-			o.Fusex_clients.WithLabelValues().Set(fusex_clients)
-		}
+		setValue(b, o.Fusex_clients, m.Fusex_clients)
 
 		// Fusex_lockedclients
 
-		fusex_lockedcs, err := strconv.ParseFloat(m.Fusex_lockedclients, 64)
-		if err == nil {
-			o.Fusex_lockedclients.WithLabelValues().Set(fusex_lockedcs)
-		}
+		setValue(b, o.Fusex_lockedclients, m.Fusex_lockedclients)
 
 		// Latency_dirs
 
-		lat_dirs, err := strconv.ParseFloat(m.Latency_dirs, 64)
-		if err == nil {
-			o.Latency_dirs.WithLabelValues().Set(lat_dirs)
-		}
+		setValue(b, o.Latency_dirs, m.Latency_dirs)
 
 		// Latency_files
 
-		lat_files, err := strconv.ParseFloat(m.Latency_files, 64)
-		if err == nil {
-			o.Latency_files.WithLabelValues().Set(lat_files)
-		}
+		setValue(b, o.Latency_files, m.Latency_files)
 
 		// Latency_pending_updates
 
-		lat_pen_upd, err := strconv.ParseFloat(m.Latency_pending_updates, 64)
-		if err == nil {
-			o.Latency_pending_updates.WithLabelValues().Set(lat_pen_upd)
-		}
+		setValue(b, o.Latency_pending_updates, m.Latency_pending_updates)
 
 		// Latencypeak_eosviewmutex_1min
 
-		lat_eosvm_1m, err := strconv.ParseFloat(m.Latencypeak_eosviewmutex_1min, 64)
-		if err == nil {
-			o.Latencypeak_eosviewmutex_1min.WithLabelValues().Set(lat_eosvm_1m)
-		}
+		setValue(b, o.Latencypeak_eosviewmutex_1min, m.Latencypeak_eosviewmutex_1min)
 
 		// Latencypeak_eosviewmutex_2min
 
-		lat_eosvm_2m, err := strconv.ParseFloat(m.Latencypeak_eosviewmutex_2min, 64)
-		if err == nil {
-			o.Latencypeak_eosviewmutex_2min.WithLabelValues().Set(lat_eosvm_2m)
-		}
+		setValue(b, o.Latencypeak_eosviewmutex_2min, m.Latencypeak_eosviewmutex_2min)
 
 		// Latencypeak_eosviewmutex_5min
 
-		lat_eosvm_5m, err := strconv.ParseFloat(m.Latencypeak_eosviewmutex_5min, 64)
-		if err == nil {
-			o.Latencypeak_eosviewmutex_5min.WithLabelValues().Set(lat_eosvm_5m)
-		}
+		setValue(b, o.Latencypeak_eosviewmutex_5min, m.Latencypeak_eosviewmutex_5min)
 
 		// Latencypeak_eosviewmutex_last
 
-		lat_eosvm_last, err := strconv.ParseFloat(m.Latencypeak_eosviewmutex_last, 64)
-		if err == nil {
-			o.Latencypeak_eosviewmutex_last.WithLabelValues().Set(lat_eosvm_last)
-		}
+		setValue(b, o.Latencypeak_eosviewmutex_last, m.Latencypeak_eosviewmutex_last)
 
 		// Qclient_rtt_ms_min
 
-		qclient_rtt_ms_min, err := strconv.ParseFloat(m.Qclient_rtt_ms_min, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_min.WithLabelValues().Set(qclient_rtt_ms_min)
-		}
+		setValue(b, o.Qclient_rtt_ms_min, m.Qclient_rtt_ms_min)
 
 		// Qclient_rtt_ms_avg
 
-		qclient_rtt_ms_avg, err := strconv.ParseFloat(m.Qclient_rtt_ms_avg, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_avg.WithLabelValues().Set(qclient_rtt_ms_avg)
-		}
+		setValue(b, o.Qclient_rtt_ms_avg, m.Qclient_rtt_ms_avg)
 
 		// Qclient_rtt_ms_max
 
-		qclient_rtt_ms_max, err := strconv.ParseFloat(m.Qclient_rtt_ms_max, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_max.WithLabelValues().Set(qclient_rtt_ms_max)
-		}
+		setValue(b, o.Qclient_rtt_ms_max, m.Qclient_rtt_ms_max)
 
 		// Qclient_rtt_ms_peak_1min
 
-		qclient_rtt_ms_peak_1min, err := strconv.ParseFloat(m.Qclient_rtt_ms_peak_1min, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_peak_1min.WithLabelValues().Set(qclient_rtt_ms_peak_1min)
-		}
+		setValue(b, o.Qclient_rtt_ms_peak_1min, m.Qclient_rtt_ms_peak_1min)
 
 		// Qclient_rtt_ms_peak_2min
 
-		qclient_rtt_ms_peak_2min, err := strconv.ParseFloat(m.Qclient_rtt_ms_peak_2min, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_peak_2min.WithLabelValues().Set(qclient_rtt_ms_peak_2min)
-		}
+		setValue(b, o.Qclient_rtt_ms_peak_2min, m.Qclient_rtt_ms_peak_2min)
 
 		// Qclient_rtt_ms_peak_5min
 
-		qclient_rtt_ms_peak_5min, err := strconv.ParseFloat(m.Qclient_rtt_ms_peak_5min, 64)
-		if err == nil {
-			o.Qclient_rtt_ms_peak_5min.WithLabelValues().Set(qclient_rtt_ms_peak_5min)
-		}
+		setValue(b, o.Qclient_rtt_ms_peak_5min, m.Qclient_rtt_ms_peak_5min)
 
 		// Memory_growth
 
-		mem_growth, err := strconv.ParseFloat(m.Memory_growth, 64)
-		if err == nil {
-			o.Memory_growth.WithLabelValues().Set(mem_growth)
-		}
+		setValue(b, o.Memory_growth, m.Memory_growth)
 
 		// Memory_resident
 
-		mem_res, err := strconv.ParseFloat(m.Memory_resident, 64)
-		if err == nil {
-			o.Memory_resident.WithLabelValues().Set(mem_res)
-		}
+		setValue(b, o.Memory_resident, m.Memory_resident)
 
 		// Memory_share
-		mem_share, err := strconv.ParseFloat(m.Memory_share, 64)
-		if err == nil {
-			o.Memory_share.WithLabelValues().Set(mem_share)
-		}
+		setValue(b, o.Memory_share, m.Memory_share)
 
 		// Memory_virtual
 
-		mem_virt, err := strconv.ParseFloat(m.Memory_virtual, 64)
-		if err == nil {
-			o.Memory_virtual.WithLabelValues().Set(mem_virt)
-		}
+		setValue(b, o.Memory_virtual, m.Memory_virtual)
 
 		// Stat_threads
 
-		stat_threads, err := strconv.ParseFloat(m.Stat_threads, 64)
-		if err == nil {
-			o.Stat_threads.WithLabelValues().Set(stat_threads)
-		}
+		setValue(b, o.Stat_threads, m.Stat_threads)
 
 		// Total_directories
 
-		total_dirs, err := strconv.ParseFloat(m.Total_directories, 64)
-		if err == nil {
-			o.Total_directories.WithLabelValues().Set(total_dirs)
-		}
+		setValue(b, o.Total_directories, m.Total_directories)
 
 		// Total_directories_changelog_avg_entry_size
-		total_dirs_clog_avg_entry_size, err := strconv.ParseFloat(m.Total_directories_changelog_avg_entry_size, 64)
-
-		if err == nil {
-			o.Total_directories_changelog_avg_entry_size.WithLabelValues().Set(total_dirs_clog_avg_entry_size)
-		}
+		setValue(b, o.Total_directories_changelog_avg_entry_size, m.Total_directories_changelog_avg_entry_size)
 
 		// Total_directories_changelog_size
 
-		total_dirs_clog_size, err := strconv.ParseFloat(m.Total_directories_changelog_size, 64)
-		if err == nil {
-			o.Total_directories_changelog_size.WithLabelValues().Set(total_dirs_clog_size)
-		}
+		setValue(b, o.Total_directories_changelog_size, m.Total_directories_changelog_size)
 
 		// Total_files
 
-		total_files, err := strconv.ParseFloat(m.Total_files, 64)
-		if err == nil {
-			o.Total_files.WithLabelValues().Set(total_files)
-		}
+		setValue(b, o.Total_files, m.Total_files)
 
 		// Total_files_changelog_avg_entry_size
 
-		total_files_clog_avg_entry_size, err := strconv.ParseFloat(m.Total_files_changelog_avg_entry_size, 64)
-		if err == nil {
-			o.Total_files_changelog_avg_entry_size.WithLabelValues().Set(total_files_clog_avg_entry_size)
-		}
+		setValue(b, o.Total_files_changelog_avg_entry_size, m.Total_files_changelog_avg_entry_size)
 
 		// Total_files_changelog_size
 
-		total_files_clog_size, err := strconv.ParseFloat(m.Total_files_changelog_size, 64)
-		if err == nil {
-			o.Total_files_changelog_size.WithLabelValues().Set(total_files_clog_size)
-		}
+		setValue(b, o.Total_files_changelog_size, m.Total_files_changelog_size)
 
 		// Uptime
 
-		uptime, err := strconv.ParseFloat(m.Uptime, 64)
-		if err == nil {
-			o.Uptime.WithLabelValues().Set(uptime)
-		}
+		setValue(b, o.Uptime, m.Uptime)
 
 		// Hanging_since
 
-		hanging_since, err := strconv.ParseFloat(m.Hanging_since, 64)
-		if err == nil {
-			o.Hanging_since.WithLabelValues().Set(hanging_since)
-		}
+		setValue(b, o.Hanging_since, m.Hanging_since)
 
 		// Cache_files_requests
-		cache_files_requests, err := strconv.ParseFloat(m.Cache_files_requests, 64)
-		if err == nil {
-			o.Cache_files_requests.WithLabelValues().Set(cache_files_requests)
-		}
+		setValue(b, o.Cache_files_requests, m.Cache_files_requests)
 
 		// Cache_files_hits
-		cache_files_hits, err := strconv.ParseFloat(m.Cache_files_hits, 64)
-		if err == nil {
-			o.Cache_files_hits.WithLabelValues().Set(cache_files_hits)
-		}
+		setValue(b, o.Cache_files_hits, m.Cache_files_hits)
 
 		// Cache_containers_requests
-		cache_containers_requests, err := strconv.ParseFloat(m.Cache_containers_requests, 64)
-		if err == nil {
-			o.Cache_containers_requests.WithLabelValues().Set(cache_containers_requests)
-		}
+		setValue(b, o.Cache_containers_requests, m.Cache_containers_requests)
 
 		// Cache_containers_hits
-		cache_containers_hits, err := strconv.ParseFloat(m.Cache_containers_hits, 64)
-		if err == nil {
-			o.Cache_containers_hits.WithLabelValues().Set(cache_containers_hits)
-		}
+		setValue(b, o.Cache_containers_hits, m.Cache_containers_hits)
 
-		switch m.Traffic_shaping_enabled {
-		case "true", "TRUE", "True", "1":
-			o.Traffic_shaping_enabled.WithLabelValues().Set(1)
-		case "false", "FALSE", "False", "0":
-			o.Traffic_shaping_enabled.WithLabelValues().Set(0)
+		if m.Traffic_shaping_enabled != nil {
+			b.set(o.Traffic_shaping_enabled, boolValue(*m.Traffic_shaping_enabled))
 		}
 
 	}
@@ -1008,42 +869,47 @@ func (o *NSCollector) collectNSDF() error {
 
 } // collectNSDF()
 
-func (o *NSActivityCollector) collectNSActivityDF() error {
+func (o *NSActivityCollector) collectNSActivityDF(ctx context.Context, b *metricBatch) error {
+
+	_, mdsact, _, err := getNSData(ctx, o.CollectorOpts)
+	if err != nil {
+		return err
+	}
 
-	for _, n := range Mdsact {
+	for _, n := range mdsact {
 		// Sum
 
 		sum, err := strconv.ParseFloat(n.Sum, 64)
 		if err == nil {
-			o.Sum.WithLabelValues(n.User, n.Operation).Set(sum)
+			b.set(o.Sum, sum, n.User, n.Operation)
 		}
 
 		// Last_5s
 
 		last_5s, err := strconv.ParseFloat(n.Last_5s, 64)
 		if err == nil {
-			o.Last_5s.WithLabelValues(n.User, n.Operation).Set(last_5s)
+			b.set(o.Last_5s, last_5s, n.User, n.Operation)
 		}
 
 		// Last_60s
 
 		last_1min, err := strconv.ParseFloat(n.Last_60s, 64)
 		if err == nil {
-			o.Last_60s.WithLabelValues(n.User, n.Operation).Set(last_1min)
+			b.set(o.Last_60s, last_1min, n.User, n.Operation)
 		}
 
 		// Last_300s
 
 		last_5min, err := strconv.ParseFloat(n.Last_300s, 64)
 		if err == nil {
-			o.Last_300s.WithLabelValues(n.User, n.Operation).Set(last_5min)
+			b.set(o.Last_300s, last_5min, n.User, n.Operation)
 		}
 
 		// Last_3600s
 
 		last_1h, err := strconv.ParseFloat(n.Last_3600s, 64)
 		if err == nil {
-			o.Last_3600s.WithLabelValues(n.User, n.Operation).Set(last_1h)
+			b.set(o.Last_3600s, last_1h, n.User, n.Operation)
 		}
 
 	}
@@ -1052,42 +918,47 @@ func (o *NSActivityCollector) collectNSActivityDF() error {
 
 } // collectNSActivityDF()
 
-func (o *NSBatchCollector) collectNSBatchDF() error {
+func (o *NSBatchCollector) collectNSBatchDF(ctx context.Context, b *metricBatch) error {
 
-	for _, n := range Mdsbatch {
+	_, _, mdsbatch, err := getNSData(ctx, o.CollectorOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range mdsbatch {
 		// Sum
 
 		sum, err := strconv.ParseFloat(n.Sum, 64)
 		if err == nil {
-			o.Sum.WithLabelValues(n.User, n.Operation, n.Level).Set(sum)
+			b.set(o.Sum, sum, n.User, n.Operation, n.Level)
 		}
 
 		// Last_5s
 
 		last_5s, err := strconv.ParseFloat(n.Last_5s, 64)
 		if err == nil {
-			o.Last_5s.WithLabelValues(n.User, n.Operation, n.Level).Set(last_5s)
+			b.set(o.Last_5s, last_5s, n.User, n.Operation, n.Level)
 		}
 
 		// Last_60s
 
 		last_1min, err := strconv.ParseFloat(n.Last_60s, 64)
 		if err == nil {
-			o.Last_60s.WithLabelValues(n.User, n.Operation, n.Level).Set(last_1min)
+			b.set(o.Last_60s, last_1min, n.User, n.Operation, n.Level)
 		}
 
 		// Last_300s
 
 		last_5min, err := strconv.ParseFloat(n.Last_300s, 64)
 		if err == nil {
-			o.Last_300s.WithLabelValues(n.User, n.Operation, n.Level).Set(last_5min)
+			b.set(o.Last_300s, last_5min, n.User, n.Operation, n.Level)
 		}
 
 		// Last_3600s
 
 		last_1h, err := strconv.ParseFloat(n.Last_3600s, 64)
 		if err == nil {
-			o.Last_3600s.WithLabelValues(n.User, n.Operation, n.Level).Set(last_1h)
+			b.set(o.Last_3600s, last_1h, n.User, n.Operation, n.Level)
 		}
 
 	}
@@ -1104,19 +975,6 @@ func (o *NSCollector) Describe(ch chan<- *prometheus.Desc) {
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *NSCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectNSDF(); err != nil {
-		log.Println("failed collecting ns metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // Describe sends the descriptors of each NSActivityCollector related metrics we have defined
 func (o *NSActivityCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range o.collectorList() {
@@ -1125,19 +983,6 @@ func (o *NSActivityCollector) Describe(ch chan<- *prometheus.Desc) {
 	//ch <- o.ScrubbingStateDesc
 }
 
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *NSActivityCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectNSActivityDF(); err != nil {
-		log.Println("failed collecting ns_activity metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
-
 // Describe sends the descriptors of each NSBatchCollector related metrics we have defined
 func (o *NSBatchCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range o.collectorList() {
@@ -1145,16 +990,3 @@ func (o *NSBatchCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 	//ch <- o.ScrubbingStateDesc
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *NSBatchCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectNSBatchDF(); err != nil {
-		log.Println("failed collecting space metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
@@ -2,20 +2,19 @@ package collector
 
 import (
 	"context"
-	"log"
 
-	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type QuotasCollector struct {
 	*CollectorOpts
-	QuotaUsedBytes        *prometheus.GaugeVec
-	QuotaMaxBytes         *prometheus.GaugeVec
-	QuotaUsedLogicalBytes *prometheus.GaugeVec
-	QuotaMaxLogicalBytes  *prometheus.GaugeVec
-	QuotaUsedFiles        *prometheus.GaugeVec
-	QuotaMaxFiles         *prometheus.GaugeVec
+	batchCollector
+	QuotaUsedBytes        *metricVec
+	QuotaMaxBytes         *metricVec
+	QuotaUsedLogicalBytes *metricVec
+	QuotaMaxLogicalBytes  *metricVec
+	QuotaUsedFiles        *metricVec
+	QuotaMaxFiles         *metricVec
 }
 
 // NewQuotasCollector creates an cluster of the QuotasCollector
@@ -26,9 +25,9 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 
 	namespace := "eos"
 
-	return &QuotasCollector{
+	o := &QuotasCollector{
 		CollectorOpts: opts,
-		QuotaUsedBytes: prometheus.NewGaugeVec(
+		QuotaUsedBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_used_bytes",
@@ -37,7 +36,7 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			},
 			[]string{"uid", "gid", "space"},
 		),
-		QuotaMaxBytes: prometheus.NewGaugeVec(
+		QuotaMaxBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_max_bytes",
@@ -46,7 +45,7 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			},
 			[]string{"uid", "gid", "space"},
 		),
-		QuotaUsedLogicalBytes: prometheus.NewGaugeVec(
+		QuotaUsedLogicalBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_used_logical_bytes",
@@ -55,7 +54,7 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			},
 			[]string{"uid", "gid", "space"},
 		),
-		QuotaMaxLogicalBytes: prometheus.NewGaugeVec(
+		QuotaMaxLogicalBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_max_logical_bytes",
@@ -64,7 +63,7 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			},
 			[]string{"uid", "gid", "space"},
 		),
-		QuotaUsedFiles: prometheus.NewGaugeVec(
+		QuotaUsedFiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_used_files",
@@ -73,7 +72,7 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			},
 			[]string{"uid", "gid", "space"},
 		),
-		QuotaMaxFiles: prometheus.NewGaugeVec(
+		QuotaMaxFiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "quota_max_files",
@@ -83,10 +82,12 @@ func NewQuotasCollector(opts *CollectorOpts) *QuotasCollector {
 			[]string{"uid", "gid", "space"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "quota metrics", collect: o.collectQuotaDF}
+	return o
 }
 
-func (o *QuotasCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *QuotasCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.QuotaUsedBytes,
 		o.QuotaMaxBytes,
 		o.QuotaUsedFiles,
@@ -96,42 +97,24 @@ func (o *QuotasCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *QuotasCollector) collectQuotaDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *QuotasCollector) collectQuotaDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	quotas, err := client.Quotas(context.Background(), "root")
+	quotas, err := client.Quotas(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	// a GaugeVector keeps its state for any combination of labels until the process is restarted.
-	// For metrics obtained from systems like EOS that do not produce a complete set of metrics (only the active metrics)
-	// then we risk to expose these metrics forever until the next process restart.
-	// To work around this, we reset the gauge vectors when collecting metrics.
-
 	// output is like this:
 	// quota=node uid=9218 space=/eos/user/ usedbytes=158090138 usedlogicalbytes=79045069 usedfiles=1546 maxbytes=0 maxlogicalbytes=0 maxfiles=0 percentageusedbytes=100.00 statusbytes=ignored statusfiles=ignored
 
-	o.QuotaUsedBytes.Reset()
-	o.QuotaMaxBytes.Reset()
-	o.QuotaUsedLogicalBytes.Reset()
-	o.QuotaMaxLogicalBytes.Reset()
-	o.QuotaUsedFiles.Reset()
-	o.QuotaMaxFiles.Reset()
-
 	for _, q := range quotas {
-		o.QuotaUsedBytes.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.UsedBytes))
-		o.QuotaMaxBytes.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.MaxBytes))
-		o.QuotaUsedLogicalBytes.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.UsedLogicalBytes))
-		o.QuotaMaxLogicalBytes.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.MaxLogicalBytes))
-		o.QuotaUsedFiles.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.UsedFiles))
-		o.QuotaMaxFiles.WithLabelValues(q.Uid, q.Gid, q.Space).Set(float64(q.MaxFiles))
+		b.set(o.QuotaUsedBytes, float64(q.UsedBytes), q.Uid, q.Gid, q.Space)
+		b.set(o.QuotaMaxBytes, float64(q.MaxBytes), q.Uid, q.Gid, q.Space)
+		b.set(o.QuotaUsedLogicalBytes, float64(q.UsedLogicalBytes), q.Uid, q.Gid, q.Space)
+		b.set(o.QuotaMaxLogicalBytes, float64(q.MaxLogicalBytes), q.Uid, q.Gid, q.Space)
+		b.set(o.QuotaUsedFiles, float64(q.UsedFiles), q.Uid, q.Gid, q.Space)
+		b.set(o.QuotaMaxFiles, float64(q.MaxFiles), q.Uid, q.Gid, q.Space)
 	}
 
 	return nil
@@ -144,16 +127,3 @@ func (o *QuotasCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *QuotasCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectQuotaDF(); err != nil {
-		log.Println("failed collecting quota  metrics:", err)
-		return
-	}
-
-	for _, collector := range o.collectorList() {
-		collector.Collect(ch)
-	}
-}
@@ -2,10 +2,7 @@ package collector
 
 import (
 	"context"
-	"log"
-	"strconv"
 
-	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -14,10 +11,11 @@ import (
 
 type RecycleCollector struct {
 	*CollectorOpts
-	UsedBytes *prometheus.GaugeVec
-	MaxBytes  *prometheus.GaugeVec
-	Lifetime  *prometheus.GaugeVec
-	Ratio     *prometheus.GaugeVec
+	batchCollector
+	UsedBytes *metricVec
+	MaxBytes  *metricVec
+	Lifetime  *metricVec
+	Ratio     *metricVec
 }
 
 // NewRecycleCollector creates an cluster of the RecycleCollector
@@ -26,9 +24,9 @@ func NewRecycleCollector(opts *CollectorOpts) *RecycleCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &RecycleCollector{
+	o := &RecycleCollector{
 		CollectorOpts: opts,
-		UsedBytes: prometheus.NewGaugeVec(
+		UsedBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "recycle_used_bytes",
@@ -37,7 +35,7 @@ func NewRecycleCollector(opts *CollectorOpts) *RecycleCollector {
 			},
 			[]string{},
 		),
-		MaxBytes: prometheus.NewGaugeVec(
+		MaxBytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "recycle_max_bytes",
@@ -46,7 +44,7 @@ func NewRecycleCollector(opts *CollectorOpts) *RecycleCollector {
 			},
 			[]string{},
 		),
-		Lifetime: prometheus.NewGaugeVec(
+		Lifetime: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "recycle_lifetime_seconds",
@@ -55,7 +53,7 @@ func NewRecycleCollector(opts *CollectorOpts) *RecycleCollector {
 			},
 			[]string{},
 		),
-		Ratio: prometheus.NewGaugeVec(
+		Ratio: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "recycle_ratio",
@@ -65,10 +63,12 @@ func NewRecycleCollector(opts *CollectorOpts) *RecycleCollector {
 			[]string{},
 		),
 	}
+	o.batchCollector = batchCollector{what: "recycle metrics", collect: o.collectRecycleDF}
+	return o
 }
 
-func (o *RecycleCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *RecycleCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.UsedBytes,
 		o.MaxBytes,
 		o.Lifetime,
@@ -76,40 +76,22 @@ func (o *RecycleCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *RecycleCollector) collectRecycleDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *RecycleCollector) collectRecycleDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.Recycle(context.Background(), "root")
+	mds, err := client.Recycle(ctx, "root")
 	if err != nil {
 		return err
 	}
 
 	for _, m := range mds {
-		usedbytes, err := strconv.ParseFloat(m.UsedBytes, 64)
-		if err == nil {
-			o.UsedBytes.WithLabelValues().Set(usedbytes)
-		}
+		setValue(b, o.UsedBytes, m.UsedBytes)
 
-		maxbytes, err := strconv.ParseFloat(m.MaxBytes, 64)
-		if err == nil {
-			o.MaxBytes.WithLabelValues().Set(maxbytes)
-		}
+		setValue(b, o.MaxBytes, m.MaxBytes)
 
-		lifetime, err := strconv.ParseFloat(m.Lifetime, 64)
-		if err == nil {
-			o.Lifetime.WithLabelValues().Set(lifetime)
-		}
+		setValue(b, o.Lifetime, m.Lifetime)
 
-		ratio, err := strconv.ParseFloat(m.Ratio, 64)
-		if err == nil {
-			o.Ratio.WithLabelValues().Set(ratio)
-		}
+		setValue(b, o.Ratio, m.Ratio)
 	}
 
 	return nil
@@ -122,16 +104,3 @@ func (o *RecycleCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *RecycleCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectRecycleDF(); err != nil {
-		log.Println("failed collecting recycle metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
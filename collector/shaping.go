@@ -2,7 +2,6 @@ package collector
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"strconv"
 
@@ -31,21 +30,22 @@ type shapingFSKey struct {
 
 type IOShapingCollector struct {
 	*CollectorOpts
+	batchCollector
 	idResolver *unixIDResolver
 
-	RateBytes *prometheus.GaugeVec
-	RateIops  *prometheus.GaugeVec
+	RateBytes *metricVec
+	RateIops  *metricVec
 
-	FSRateBytes *prometheus.GaugeVec
-	FSRateIops  *prometheus.GaugeVec
+	FSRateBytes *metricVec
+	FSRateIops  *metricVec
 
-	AllRateBytes *prometheus.GaugeVec
-	AllRateIops  *prometheus.GaugeVec
-	AllEntries   *prometheus.GaugeVec
+	AllRateBytes *metricVec
+	AllRateIops  *metricVec
+	AllEntries   *metricVec
 
 	// System metrics
-	SystemLoopDurationUs   *prometheus.GaugeVec
-	ReportsProcessedPerSec *prometheus.GaugeVec
+	SystemLoopDurationUs   *metricVec
+	ReportsProcessedPerSec *metricVec
 }
 
 func NewIOShapingCollector(opts *CollectorOpts) *IOShapingCollector {
@@ -59,100 +59,96 @@ func NewIOShapingCollector(opts *CollectorOpts) *IOShapingCollector {
 	systemLabels := []string{"loop_name", "stat"}
 	reportLabels := []string{"stat"}
 
-	return &IOShapingCollector{
+	o := &IOShapingCollector{
 		CollectorOpts: opts,
 		idResolver:    newUnixIDResolver(),
 
-		RateBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		RateBytes: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_rate_bytes",
 			Help:        "IO shaping throughput in bytes per second",
 			ConstLabels: labels,
 		}, standardLabels),
 
-		RateIops: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		RateIops: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_rate_iops",
 			Help:        "IO shaping operations per second",
 			ConstLabels: labels,
 		}, standardLabels),
 
-		FSRateBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		FSRateBytes: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_fs_rate_bytes",
 			Help:        "IO shaping filesystem throughput in bytes per second",
 			ConstLabels: labels,
 		}, fsLabels),
 
-		FSRateIops: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		FSRateIops: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_fs_rate_iops",
 			Help:        "IO shaping filesystem operations per second",
 			ConstLabels: labels,
 		}, fsLabels),
 
-		AllRateBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		AllRateBytes: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_all_rate_bytes",
 			Help:        "IO shaping all-tags throughput in bytes per second",
 			ConstLabels: labels,
 		}, allLabels),
 
-		AllRateIops: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		AllRateIops: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_all_rate_iops",
 			Help:        "IO shaping all-tags operations per second",
 			ConstLabels: labels,
 		}, allLabels),
 
-		AllEntries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		AllEntries: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_all_entries",
 			Help:        "Number of entries returned by eos io shaping ls --all --json for the configured window.",
 			ConstLabels: labels,
 		}, []string{"window_sec"}),
 
-		SystemLoopDurationUs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		SystemLoopDurationUs: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_sys_loop_duration_microseconds",
 			Help:        "System thread loop duration in microseconds",
 			ConstLabels: labels,
 		}, systemLabels),
 
-		ReportsProcessedPerSec: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		ReportsProcessedPerSec: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_reports_processed_per_sec",
 			Help:        "FST IO reports processed per second",
 			ConstLabels: labels,
 		}, reportLabels),
 	}
+	o.batchCollector = batchCollector{what: "IO shaping metrics", collect: o.collectIOShaping}
+	return o
 }
 
-func (o *IOShapingCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *IOShapingCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.RateBytes, o.RateIops, o.FSRateBytes, o.FSRateIops, o.AllRateBytes, o.AllRateIops, o.AllEntries, o.SystemLoopDurationUs, o.ReportsProcessedPerSec,
 	}
 }
 
-func (o *IOShapingCollector) collectIOShaping() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		return fmt.Errorf("failed to create eosclient: %w", err)
-	}
+func (o *IOShapingCollector) collectIOShaping(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
 	windows := []int{15, 300}
 	var allStats []*eosclient.IOShapingAllStat
 
 	for _, win := range windows {
-		stats, err := client.ListIOShapingAll(context.Background(), win)
+		stats, err := client.ListIOShapingAll(ctx, win)
 		if err != nil {
 			log.Printf("failed to collect IO shaping all-tags stats for window %ds: %v", win, err)
 			continue
 		}
-		o.AllEntries.WithLabelValues(strconv.Itoa(win)).Set(float64(countIOShapingAllEntries(stats)))
+		b.set(o.AllEntries, float64(countIOShapingAllEntries(stats)), strconv.Itoa(win))
 		allStats = append(allStats, stats...)
 	}
 
@@ -163,34 +159,34 @@ func (o *IOShapingCollector) collectIOShaping() error {
 	standardStats, fsStats, _ := projectIOShapingAll(allStats)
 
 	for key, values := range standardStats {
-		setProjectedMetric(o.RateBytes, key.Type, key.ID, key.WindowSec, "read", values.ReadRateBps)
-		setProjectedMetric(o.RateBytes, key.Type, key.ID, key.WindowSec, "write", values.WriteRateBps)
-		setProjectedMetric(o.RateIops, key.Type, key.ID, key.WindowSec, "read", values.ReadIops)
-		setProjectedMetric(o.RateIops, key.Type, key.ID, key.WindowSec, "write", values.WriteIops)
+		setProjectedMetric(b, o.RateBytes, key.Type, key.ID, key.WindowSec, "read", values.ReadRateBps)
+		setProjectedMetric(b, o.RateBytes, key.Type, key.ID, key.WindowSec, "write", values.WriteRateBps)
+		setProjectedMetric(b, o.RateIops, key.Type, key.ID, key.WindowSec, "read", values.ReadIops)
+		setProjectedMetric(b, o.RateIops, key.Type, key.ID, key.WindowSec, "write", values.WriteIops)
 	}
 
 	for key, values := range fsStats {
-		setFSProjectedMetric(o.FSRateBytes, key.NodeID, key.FSID, key.WindowSec, "read", values.ReadRateBps)
-		setFSProjectedMetric(o.FSRateBytes, key.NodeID, key.FSID, key.WindowSec, "write", values.WriteRateBps)
-		setFSProjectedMetric(o.FSRateIops, key.NodeID, key.FSID, key.WindowSec, "read", values.ReadIops)
-		setFSProjectedMetric(o.FSRateIops, key.NodeID, key.FSID, key.WindowSec, "write", values.WriteIops)
+		setFSProjectedMetric(b, o.FSRateBytes, key.NodeID, key.FSID, key.WindowSec, "read", values.ReadRateBps)
+		setFSProjectedMetric(b, o.FSRateBytes, key.NodeID, key.FSID, key.WindowSec, "write", values.WriteRateBps)
+		setFSProjectedMetric(b, o.FSRateIops, key.NodeID, key.FSID, key.WindowSec, "read", values.ReadIops)
+		setFSProjectedMetric(b, o.FSRateIops, key.NodeID, key.FSID, key.WindowSec, "write", values.WriteIops)
 	}
 
 	for _, s := range allStats {
 		if s.Type == "system" {
-			o.collectSystemMetrics(s)
+			o.collectSystemMetrics(b, s)
 			continue
 		}
 
 		uidName := o.idResolver.ResolveUser(s.UID)
 		gidName := o.idResolver.ResolveGroup(s.GID)
 
-		setAllMetric := func(vec *prometheus.GaugeVec, operation, valStr string) {
+		setAllMetric := func(vec *metricVec, operation, valStr string) {
 			if valStr == "" {
 				return
 			}
 			if val, err := strconv.ParseFloat(valStr, 64); err == nil {
-				vec.WithLabelValues(s.NodeID, s.FSID, s.App, s.UID, uidName, s.GID, gidName, s.WindowSec, operation).Set(val)
+				b.set(vec, val, s.NodeID, s.FSID, s.App, s.UID, uidName, s.GID, gidName, s.WindowSec, operation)
 			}
 		}
 
@@ -213,13 +209,13 @@ func countIOShapingAllEntries(stats []*eosclient.IOShapingAllStat) int {
 	return entries
 }
 
-func (o *IOShapingCollector) collectSystemMetrics(s *eosclient.IOShapingAllStat) {
+func (o *IOShapingCollector) collectSystemMetrics(b *metricBatch, s *eosclient.IOShapingAllStat) {
 	setSysMetric := func(loopName, statName, valStr string) {
 		if valStr == "" {
 			return
 		}
 		if val, err := strconv.ParseFloat(valStr, 64); err == nil {
-			o.SystemLoopDurationUs.WithLabelValues(loopName, statName).Set(val)
+			b.set(o.SystemLoopDurationUs, val, loopName, statName)
 		}
 	}
 
@@ -233,7 +229,7 @@ func (o *IOShapingCollector) collectSystemMetrics(s *eosclient.IOShapingAllStat)
 
 	if s.ReportsProcessedPerSecMean != "" {
 		if val, err := strconv.ParseFloat(s.ReportsProcessedPerSecMean, 64); err == nil {
-			o.ReportsProcessedPerSec.WithLabelValues("mean").Set(val)
+			b.set(o.ReportsProcessedPerSec, val, "mean")
 		}
 	}
 }
@@ -301,12 +297,12 @@ func parseShapingFloat(valStr string) float64 {
 	return val
 }
 
-func setProjectedMetric(vec *prometheus.GaugeVec, statType, id, windowSec, operation string, val float64) {
-	vec.WithLabelValues(statType, id, windowSec, operation).Set(val)
+func setProjectedMetric(b *metricBatch, vec *metricVec, statType, id, windowSec, operation string, val float64) {
+	b.set(vec, val, statType, id, windowSec, operation)
 }
 
-func setFSProjectedMetric(vec *prometheus.GaugeVec, nodeID, fsid, windowSec, operation string, val float64) {
-	vec.WithLabelValues(nodeID, fsid, windowSec, operation).Set(val)
+func setFSProjectedMetric(b *metricBatch, vec *metricVec, nodeID, fsid, windowSec, operation string, val float64) {
+	b.set(vec, val, nodeID, fsid, windowSec, operation)
 }
 
 func (o *IOShapingCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -314,20 +310,3 @@ func (o *IOShapingCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-func (o *IOShapingCollector) Collect(ch chan<- prometheus.Metric) {
-	for _, metric := range o.collectorList() {
-		if gaugeVec, ok := metric.(*prometheus.GaugeVec); ok {
-			gaugeVec.Reset()
-		}
-	}
-
-	if err := o.collectIOShaping(); err != nil {
-		log.Println("failed collecting IO shaping metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
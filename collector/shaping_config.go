@@ -16,17 +16,18 @@ const ioShapingConfigRefreshInterval = 0 * time.Minute
 
 type IOShapingConfigCollector struct {
 	*CollectorOpts
+	batchCollector
 
 	mu          sync.Mutex
 	lastRefresh time.Time
 	config      *eosclient.IOShapingConfig
 
-	Enabled                     *prometheus.GaugeVec
-	EstimatorsUpdatePeriodMs    *prometheus.GaugeVec
-	FstIOPolicyUpdatePeriodMs   *prometheus.GaugeVec
-	FstIOStatsReportingPeriodMs *prometheus.GaugeVec
-	DetailFilesystem            *prometheus.GaugeVec
-	SystemStatsTimeWindowSec    *prometheus.GaugeVec
+	Enabled                     *metricVec
+	EstimatorsUpdatePeriodMs    *metricVec
+	FstIOPolicyUpdatePeriodMs   *metricVec
+	FstIOStatsReportingPeriodMs *metricVec
+	DetailFilesystem            *metricVec
+	SystemStatsTimeWindowSec    *metricVec
 }
 
 func NewIOShapingConfigCollector(opts *CollectorOpts) *IOShapingConfigCollector {
@@ -34,55 +35,57 @@ func NewIOShapingConfigCollector(opts *CollectorOpts) *IOShapingConfigCollector
 	labels := prometheus.Labels{"cluster": cluster}
 	namespace := "eos"
 
-	return &IOShapingConfigCollector{
+	o := &IOShapingConfigCollector{
 		CollectorOpts: opts,
 
-		Enabled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Enabled: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_enabled",
 			Help:        "Traffic shaping configuration status (1 if enabled, 0 if disabled).",
 			ConstLabels: labels,
 		}, []string{}),
 
-		EstimatorsUpdatePeriodMs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		EstimatorsUpdatePeriodMs: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_estimators_update_period_milliseconds",
 			Help:        "Configured IO shaping estimators update period in milliseconds.",
 			ConstLabels: labels,
 		}, []string{}),
 
-		FstIOPolicyUpdatePeriodMs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		FstIOPolicyUpdatePeriodMs: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_fst_io_policy_update_period_milliseconds",
 			Help:        "Configured FST IO policy update period in milliseconds.",
 			ConstLabels: labels,
 		}, []string{}),
 
-		FstIOStatsReportingPeriodMs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		FstIOStatsReportingPeriodMs: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_fst_io_stats_reporting_period_milliseconds",
 			Help:        "Configured FST IO stats reporting period in milliseconds.",
 			ConstLabels: labels,
 		}, []string{}),
 
-		DetailFilesystem: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		DetailFilesystem: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_detail_filesystem",
 			Help:        "Traffic shaping stats detail level (1 if filesystem, 0 otherwise).",
 			ConstLabels: labels,
 		}, []string{}),
 
-		SystemStatsTimeWindowSec: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		SystemStatsTimeWindowSec: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_config_system_stats_time_window_seconds",
 			Help:        "Configured IO shaping system stats time window in seconds.",
 			ConstLabels: labels,
 		}, []string{}),
 	}
+	o.batchCollector = batchCollector{what: "IO shaping config metrics", collect: o.collectIOShapingConfig}
+	return o
 }
 
-func (o *IOShapingConfigCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *IOShapingConfigCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.Enabled,
 		o.EstimatorsUpdatePeriodMs,
 		o.FstIOPolicyUpdatePeriodMs,
@@ -92,16 +95,10 @@ func (o *IOShapingConfigCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *IOShapingConfigCollector) fetchIOShapingConfig() (*eosclient.IOShapingConfig, error) {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create eosclient: %w", err)
-	}
+func (o *IOShapingConfigCollector) fetchIOShapingConfig(ctx context.Context) (*eosclient.IOShapingConfig, error) {
+	client := o.Client
 
-	config, err := client.ListIOShapingConfig(context.Background())
+	config, err := client.ListIOShapingConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect IO shaping config: %w", err)
 	}
@@ -109,7 +106,7 @@ func (o *IOShapingConfigCollector) fetchIOShapingConfig() (*eosclient.IOShapingC
 	return config, nil
 }
 
-func (o *IOShapingConfigCollector) configForScrape() (*eosclient.IOShapingConfig, error) {
+func (o *IOShapingConfigCollector) configForScrape(ctx context.Context) (*eosclient.IOShapingConfig, error) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
@@ -117,7 +114,7 @@ func (o *IOShapingConfigCollector) configForScrape() (*eosclient.IOShapingConfig
 		return o.config, nil
 	}
 
-	config, err := o.fetchIOShapingConfig()
+	config, err := o.fetchIOShapingConfig(ctx)
 	if err != nil {
 		if o.config != nil {
 			log.Println("failed refreshing IO shaping config metrics, using cached values:", err)
@@ -131,38 +128,38 @@ func (o *IOShapingConfigCollector) configForScrape() (*eosclient.IOShapingConfig
 	return o.config, nil
 }
 
-func setShapingConfigGaugeFromString(vec *prometheus.GaugeVec, valStr string) {
+func setShapingConfigGaugeFromString(b *metricBatch, vec *metricVec, valStr string) {
 	if valStr == "" {
 		return
 	}
 	if val, err := strconv.ParseFloat(valStr, 64); err == nil {
-		vec.WithLabelValues().Set(val)
+		b.set(vec, val)
 	}
 }
 
-func (o *IOShapingConfigCollector) collectIOShapingConfig() error {
-	config, err := o.configForScrape()
+func (o *IOShapingConfigCollector) collectIOShapingConfig(ctx context.Context, b *metricBatch) error {
+	config, err := o.configForScrape(ctx)
 	if err != nil {
 		return err
 	}
 
 	if config.Enabled {
-		o.Enabled.WithLabelValues().Set(1)
+		b.set(o.Enabled, 1)
 	} else {
-		o.Enabled.WithLabelValues().Set(0)
+		b.set(o.Enabled, 0)
 	}
 
-	setShapingConfigGaugeFromString(o.EstimatorsUpdatePeriodMs, config.EstimatorsUpdatePeriodMs)
-	setShapingConfigGaugeFromString(o.FstIOPolicyUpdatePeriodMs, config.FstIOPolicyUpdatePeriodMs)
-	setShapingConfigGaugeFromString(o.FstIOStatsReportingPeriodMs, config.FstIOStatsReportingPeriodMs)
+	setShapingConfigGaugeFromString(b, o.EstimatorsUpdatePeriodMs, config.EstimatorsUpdatePeriodMs)
+	setShapingConfigGaugeFromString(b, o.FstIOPolicyUpdatePeriodMs, config.FstIOPolicyUpdatePeriodMs)
+	setShapingConfigGaugeFromString(b, o.FstIOStatsReportingPeriodMs, config.FstIOStatsReportingPeriodMs)
 
 	if config.DetailFilesystem {
-		o.DetailFilesystem.WithLabelValues().Set(1)
+		b.set(o.DetailFilesystem, 1)
 	} else {
-		o.DetailFilesystem.WithLabelValues().Set(0)
+		b.set(o.DetailFilesystem, 0)
 	}
 
-	setShapingConfigGaugeFromString(o.SystemStatsTimeWindowSec, config.SystemStatsTimeWindowSeconds)
+	setShapingConfigGaugeFromString(b, o.SystemStatsTimeWindowSec, config.SystemStatsTimeWindowSeconds)
 
 	return nil
 }
@@ -172,20 +169,3 @@ func (o *IOShapingConfigCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-func (o *IOShapingConfigCollector) Collect(ch chan<- prometheus.Metric) {
-	for _, metric := range o.collectorList() {
-		if gaugeVec, ok := metric.(*prometheus.GaugeVec); ok {
-			gaugeVec.Reset()
-		}
-	}
-
-	if err := o.collectIOShapingConfig(); err != nil {
-		log.Println("failed collecting IO shaping config metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
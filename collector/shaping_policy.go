@@ -3,18 +3,17 @@ package collector
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 
-	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type IOShapingPolicyCollector struct {
 	*CollectorOpts
+	batchCollector
 
 	// Single grouped metric for all policy limits and reservations
-	PolicyBytes *prometheus.GaugeVec
+	PolicyBytes *metricVec
 }
 
 func NewIOShapingPolicyCollector(opts *CollectorOpts) *IOShapingPolicyCollector {
@@ -25,33 +24,29 @@ func NewIOShapingPolicyCollector(opts *CollectorOpts) *IOShapingPolicyCollector
 	// Split labels: rule (limit/reservation/controller_limit) and operation (read/write)
 	standardLabels := []string{"type", "id", "rule", "operation"}
 
-	return &IOShapingPolicyCollector{
+	o := &IOShapingPolicyCollector{
 		CollectorOpts: opts,
-		PolicyBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		PolicyBytes: newGaugeVec(prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Name:        "io_shaping_policy_bytes",
 			Help:        "Configured limits and reservations in bytes per second (0 if user policy is disabled, but controller limits bypass this)",
 			ConstLabels: labels,
 		}, standardLabels),
 	}
+	o.batchCollector = batchCollector{what: "IO shaping policy metrics", collect: o.collectIOShapingPolicies}
+	return o
 }
 
-func (o *IOShapingPolicyCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *IOShapingPolicyCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.PolicyBytes,
 	}
 }
 
-func (o *IOShapingPolicyCollector) collectIOShapingPolicies() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		return fmt.Errorf("failed to create eosclient: %w", err)
-	}
+func (o *IOShapingPolicyCollector) collectIOShapingPolicies(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	policies, err := client.ListIOShapingPolicies(context.Background())
+	policies, err := client.ListIOShapingPolicies(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect IO shaping policies: %w", err)
 	}
@@ -77,7 +72,7 @@ func (o *IOShapingPolicyCollector) collectIOShapingPolicies() error {
 				}
 			}
 
-			o.PolicyBytes.WithLabelValues(p.Type, p.ID, ruleName, operation).Set(valToSet)
+			b.set(o.PolicyBytes, valToSet, p.Type, p.ID, ruleName, operation)
 		}
 
 		setMetric("limit", "read", p.LimitReadBytes)
@@ -96,21 +91,3 @@ func (o *IOShapingPolicyCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-func (o *IOShapingPolicyCollector) Collect(ch chan<- prometheus.Metric) {
-	// Reset the GaugeVec before scrape
-	for _, metric := range o.collectorList() {
-		if gaugeVec, ok := metric.(*prometheus.GaugeVec); ok {
-			gaugeVec.Reset()
-		}
-	}
-
-	if err := o.collectIOShapingPolicies(); err != nil {
-		log.Println("failed collecting IO shaping policy metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
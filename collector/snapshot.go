@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Snapshot is a scrape-scoped cache of eos command outputs. Collectors sharing
+// a Snapshot as their executor run each distinct command line at most once per
+// scrape: the first caller runs it and concurrent or later callers reuse the
+// output until Reset starts the next scrape. A run cut by the context of its
+// caller, e.g. the timeout of its collector, is not cached.
+type Snapshot struct {
+	next eosclient.Executor
+
+	mu      sync.Mutex
+	entries map[string]*snapshotEntry
+
+	Requests *prometheus.CounterVec
+}
+
+type snapshotEntry struct {
+	done   chan struct{}
+	stdout string
+	stderr string
+	err    error
+
+	abandoned bool // the run was cut by the context of its caller, nothing is cached
+}
+
+var _ eosclient.Executor = &Snapshot{}
+
+// NewSnapshot returns a Snapshot running the cache misses through next
+func NewSnapshot(next eosclient.Executor, cluster string) *Snapshot {
+	return &Snapshot{
+		next:    next,
+		entries: make(map[string]*snapshotEntry),
+		Requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   "eos",
+				Name:        "command_cache_requests_total",
+				Help:        "Number of eos commands served from the per-scrape snapshot cache (hit) or run against EOS (miss)",
+				ConstLabels: prometheus.Labels{"cluster": cluster},
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// Fork returns an empty Snapshot running its misses through the same executor
+// and accounting them in the same metrics. It is used to cache the commands of
+// collectors that are refreshed independently from the scrapes.
+func (s *Snapshot) Fork() *Snapshot {
+	return &Snapshot{
+		next:     s.next,
+		entries:  make(map[string]*snapshotEntry),
+		Requests: s.Requests,
+	}
+}
+
+// Reset drops the cached outputs so that the next scrape runs the commands again
+func (s *Snapshot) Reset() {
+	s.mu.Lock()
+	s.entries = make(map[string]*snapshotEntry)
+	s.mu.Unlock()
+}
+
+// scopeKey is the context key of the scope of a Snapshot
+type scopeKey struct{ snapshot *Snapshot }
+
+// WithScope returns a context in which the commands run through the snapshot are
+// cached in an empty scope of their own, apart from the other scopes. It lets
+// concurrent collections share a Snapshot, each one from fresh outputs.
+func (s *Snapshot) WithScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{s}, s.Fork())
+}
+
+func (s *Snapshot) Execute(ctx context.Context, args ...string) (string, string, error) {
+	if scope, ok := ctx.Value(scopeKey{s}).(*Snapshot); ok {
+		return scope.Execute(ctx, args...)
+	}
+	key := strings.Join(args, "\x00")
+
+	for {
+		s.mu.Lock()
+		entry, ok := s.entries[key]
+		if !ok {
+			entry = &snapshotEntry{done: make(chan struct{})}
+			s.entries[key] = entry
+		}
+		s.mu.Unlock()
+
+		if ok {
+			select {
+			case <-entry.done:
+				if entry.abandoned {
+					continue
+				}
+				s.Requests.WithLabelValues("hit").Inc()
+				return entry.stdout, entry.stderr, entry.err
+			case <-ctx.Done():
+				// Given up before the output came, neither a hit nor a miss
+				return "", "", ctx.Err()
+			}
+		}
+
+		s.Requests.WithLabelValues("miss").Inc()
+		stdout, stderr, err := s.next.Execute(ctx, args...)
+		if ctx.Err() != nil {
+			// The command was cut by the deadline of this caller, which the other
+			// collectors may not share: the entry is dropped for them to run it again
+			s.mu.Lock()
+			if s.entries[key] == entry {
+				delete(s.entries, key)
+			}
+			s.mu.Unlock()
+			entry.abandoned = true
+			close(entry.done)
+			return stdout, stderr, err
+		}
+		entry.stdout, entry.stderr, entry.err = stdout, stderr, err
+		close(entry.done)
+		return stdout, stderr, err
+	}
+}
+
+// Describe sends the descriptors of the cache metrics
+func (s *Snapshot) Describe(ch chan<- *prometheus.Desc) {
+	s.Requests.Describe(ch)
+}
+
+// Collect sends the cache metrics to the provided prometheus channel
+func (s *Snapshot) Collect(ch chan<- prometheus.Metric) {
+	s.Requests.Collect(ch)
+}
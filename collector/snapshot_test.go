@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type countingExecutor struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (e *countingExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.calls == nil {
+		e.calls = make(map[string]int)
+	}
+	key := strings.Join(args, " ")
+	e.calls[key]++
+	return key, "", nil
+}
+
+func TestSnapshotRunsEachCommandOncePerScrape(t *testing.T) {
+	next := &countingExecutor{}
+	snapshot := NewSnapshot(next, "test")
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, _, _ := snapshot.Execute(ctx, "ns", "stat", "-m"); out != "ns stat -m" {
+				t.Errorf("unexpected output %q", out)
+			}
+		}()
+	}
+	wg.Wait()
+	snapshot.Execute(ctx, "-r", "0", "0", "who", "-a", "-m")
+
+	if got := next.calls["ns stat -m"]; got != 1 {
+		t.Fatalf("expected ns stat -m to run once, ran %d times", got)
+	}
+	if got := testutil.ToFloat64(snapshot.Requests.WithLabelValues("hit")); got != 3 {
+		t.Fatalf("expected 3 cache hits, got %v", got)
+	}
+	if got := testutil.ToFloat64(snapshot.Requests.WithLabelValues("miss")); got != 2 {
+		t.Fatalf("expected 2 cache misses, got %v", got)
+	}
+
+	snapshot.Reset()
+	snapshot.Execute(ctx, "ns", "stat", "-m")
+	if got := next.calls["ns stat -m"]; got != 2 {
+		t.Fatalf("expected ns stat -m to run again after Reset, ran %d times", got)
+	}
+}
+
+// slowExecutor answers after delay unless the context is done first
+type slowExecutor struct {
+	delay   time.Duration
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (e *slowExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	e.calls.Add(1)
+	e.started <- struct{}{}
+	select {
+	case <-time.After(e.delay):
+		return "output", "", nil
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+}
+
+func TestSnapshotDoesNotCacheTheTimeoutOfACaller(t *testing.T) {
+	next := &slowExecutor{delay: 50 * time.Millisecond, started: make(chan struct{}, 2)}
+	snapshot := NewSnapshot(next, "test")
+
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	shortErr := make(chan error, 1)
+	go func() {
+		_, _, err := snapshot.Execute(short, "ns", "stat", "-m")
+		shortErr <- err
+	}()
+	<-next.started
+
+	long, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, _, err := snapshot.Execute(long, "ns", "stat", "-m")
+	if err != nil || out != "output" {
+		t.Fatalf("expected the caller with the longer deadline to get the output, got %q, %v", out, err)
+	}
+	if err := <-shortErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller with the shorter deadline to time out, got %v", err)
+	}
+	if got := next.calls.Load(); got != 2 {
+		t.Fatalf("expected the command to run again after the timeout, ran %d times", got)
+	}
+
+	if out, _, err := snapshot.Execute(context.Background(), "ns", "stat", "-m"); err != nil || out != "output" {
+		t.Fatalf("expected the successful run to be cached, got %q, %v", out, err)
+	}
+	if got := next.calls.Load(); got != 2 {
+		t.Fatalf("expected the successful run to be served from the cache, ran %d times", got)
+	}
+}
+
+func TestSnapshotDoesNotCountAWaiterGivingUp(t *testing.T) {
+	next := &slowExecutor{delay: 50 * time.Millisecond, started: make(chan struct{}, 1)}
+	snapshot := NewSnapshot(next, "test")
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := snapshot.Execute(context.Background(), "ns", "stat", "-m")
+		done <- err
+	}()
+	<-next.started
+
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := snapshot.Execute(short, "ns", "stat", "-m"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the waiter to time out, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(snapshot.Requests.WithLabelValues("hit")); got != 0 {
+		t.Fatalf("expected no cache hit, got %v", got)
+	}
+	if got := testutil.ToFloat64(snapshot.Requests.WithLabelValues("miss")); got != 1 {
+		t.Fatalf("expected 1 cache miss, got %v", got)
+	}
+}
+
+func TestSnapshotScopes(t *testing.T) {
+	next := &countingExecutor{}
+	snapshot := NewSnapshot(next, "test")
+	first := snapshot.WithScope(context.Background())
+	second := snapshot.WithScope(context.Background())
+
+	for _, ctx := range []context.Context{first, first, second, second} {
+		snapshot.Execute(ctx, "ns", "stat", "-m")
+	}
+	if got := next.calls["ns stat -m"]; got != 2 {
+		t.Fatalf("expected ns stat -m to run once per scope, ran %d times", got)
+	}
+	if got := testutil.ToFloat64(snapshot.Requests.WithLabelValues("hit")); got != 2 {
+		t.Fatalf("expected 2 cache hits, got %v", got)
+	}
+}
@@ -2,11 +2,8 @@ package collector
 
 import (
 	"context"
-	"log"
-	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/cern-eos/eos_exporter/eosclient"
 )
 
 /*
@@ -45,35 +42,36 @@ sum.stat.disk.bw?configstatus@rw=63069
 
 type SpaceCollector struct {
 	*CollectorOpts
-	CfgGroupSize                         *prometheus.GaugeVec
-	CfgGroupMod                          *prometheus.GaugeVec
-	Nofs                                 *prometheus.GaugeVec
-	AvgStatDiskLoad                      *prometheus.GaugeVec
-	SigStatDiskLoad                      *prometheus.GaugeVec
-	SumStatDiskReadratemb                *prometheus.GaugeVec
-	SumStatDiskWriteratemb               *prometheus.GaugeVec
-	SumStatNetEthratemib                 *prometheus.GaugeVec
-	SumStatNetInratemib                  *prometheus.GaugeVec
-	SumStatNetOutratemib                 *prometheus.GaugeVec
-	SumStatRopen                         *prometheus.GaugeVec
-	SumStatWopen                         *prometheus.GaugeVec
-	SumStatStatfsUsedbytes               *prometheus.GaugeVec
-	SumStatStatfsFreebytes               *prometheus.GaugeVec
-	SumStatStatfsCapacity                *prometheus.GaugeVec
-	SumStatUsedfiles                     *prometheus.GaugeVec
-	SumStatStatfsFfiles                  *prometheus.GaugeVec
-	SumStatStatfsFiles                   *prometheus.GaugeVec
-	SumStatStatfsCapacityConfigstatusRw  *prometheus.GaugeVec
-	SumNofsConfigstatusRw                *prometheus.GaugeVec
-	CfgQuota                             *prometheus.GaugeVec
-	CfgNominalsize                       *prometheus.GaugeVec
-	CfgBalancer                          *prometheus.GaugeVec
-	CfgBalancerThreshold                 *prometheus.GaugeVec
-	SumStatBalancerRunning               *prometheus.GaugeVec
-	SumStatDrainerRunning                *prometheus.GaugeVec
-	SumStatDiskIopsConfigstatusRw        *prometheus.GaugeVec
-	SumStatDiskBwConfigstatusRw          *prometheus.GaugeVec
-	SumStatStatfsFreebytesConfigstatusRw *prometheus.GaugeVec
+	batchCollector
+	CfgGroupSize                         *metricVec
+	CfgGroupMod                          *metricVec
+	Nofs                                 *metricVec
+	AvgStatDiskLoad                      *metricVec
+	SigStatDiskLoad                      *metricVec
+	SumStatDiskReadratemb                *metricVec
+	SumStatDiskWriteratemb               *metricVec
+	SumStatNetEthratemib                 *metricVec
+	SumStatNetInratemib                  *metricVec
+	SumStatNetOutratemib                 *metricVec
+	SumStatRopen                         *metricVec
+	SumStatWopen                         *metricVec
+	SumStatStatfsUsedbytes               *metricVec
+	SumStatStatfsFreebytes               *metricVec
+	SumStatStatfsCapacity                *metricVec
+	SumStatUsedfiles                     *metricVec
+	SumStatStatfsFfiles                  *metricVec
+	SumStatStatfsFiles                   *metricVec
+	SumStatStatfsCapacityConfigstatusRw  *metricVec
+	SumNofsConfigstatusRw                *metricVec
+	CfgQuota                             *metricVec
+	CfgNominalsize                       *metricVec
+	CfgBalancer                          *metricVec
+	CfgBalancerThreshold                 *metricVec
+	SumStatBalancerRunning               *metricVec
+	SumStatDrainerRunning                *metricVec
+	SumStatDiskIopsConfigstatusRw        *metricVec
+	SumStatDiskBwConfigstatusRw          *metricVec
+	SumStatStatfsFreebytesConfigstatusRw *metricVec
 }
 
 // NewSpaceCollector creates an cluster of the SpaceCollector
@@ -82,9 +80,9 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 	labels := make(prometheus.Labels)
 	labels["cluster"] = cluster
 	namespace := "eos"
-	return &SpaceCollector{
+	o := &SpaceCollector{
 		CollectorOpts: opts,
-		CfgGroupSize: prometheus.NewGaugeVec(
+		CfgGroupSize: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_groupsize",
@@ -93,7 +91,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		CfgGroupMod: prometheus.NewGaugeVec(
+		CfgGroupMod: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_groupmod",
@@ -102,7 +100,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		Nofs: prometheus.NewGaugeVec(
+		Nofs: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_nofs",
@@ -111,7 +109,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		AvgStatDiskLoad: prometheus.NewGaugeVec(
+		AvgStatDiskLoad: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_load_avg",
@@ -120,7 +118,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SigStatDiskLoad: prometheus.NewGaugeVec(
+		SigStatDiskLoad: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_load_sig",
@@ -129,7 +127,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatDiskReadratemb: prometheus.NewGaugeVec(
+		SumStatDiskReadratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_readratemb",
@@ -138,7 +136,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatDiskWriteratemb: prometheus.NewGaugeVec(
+		SumStatDiskWriteratemb: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_writeratemb",
@@ -147,7 +145,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatNetEthratemib: prometheus.NewGaugeVec(
+		SumStatNetEthratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_net_ethratemib",
@@ -156,7 +154,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatNetInratemib: prometheus.NewGaugeVec(
+		SumStatNetInratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_net_inratemib",
@@ -165,7 +163,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatNetOutratemib: prometheus.NewGaugeVec(
+		SumStatNetOutratemib: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_net_outratemib",
@@ -174,7 +172,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatRopen: prometheus.NewGaugeVec(
+		SumStatRopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_ropen",
@@ -183,7 +181,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatWopen: prometheus.NewGaugeVec(
+		SumStatWopen: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_disk_wopen",
@@ -192,7 +190,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsUsedbytes: prometheus.NewGaugeVec(
+		SumStatStatfsUsedbytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_usedbytes",
@@ -201,7 +199,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsFreebytes: prometheus.NewGaugeVec(
+		SumStatStatfsFreebytes: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_freebytes",
@@ -210,7 +208,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsCapacity: prometheus.NewGaugeVec(
+		SumStatStatfsCapacity: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_sizebytes",
@@ -219,7 +217,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatUsedfiles: prometheus.NewGaugeVec(
+		SumStatUsedfiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_usedfiles",
@@ -228,7 +226,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsFfiles: prometheus.NewGaugeVec(
+		SumStatStatfsFfiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_freefiles",
@@ -237,7 +235,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsFiles: prometheus.NewGaugeVec(
+		SumStatStatfsFiles: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_files",
@@ -246,7 +244,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsCapacityConfigstatusRw: prometheus.NewGaugeVec(
+		SumStatStatfsCapacityConfigstatusRw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   "eos",
 				Name:        "space_statfs_sizebytes_configrw",
@@ -255,7 +253,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumNofsConfigstatusRw: prometheus.NewGaugeVec(
+		SumNofsConfigstatusRw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_nofs_configrw",
@@ -264,7 +262,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		CfgQuota: prometheus.NewGaugeVec(
+		CfgQuota: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_quota",
@@ -273,7 +271,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		CfgNominalsize: prometheus.NewGaugeVec(
+		CfgNominalsize: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_nominalsize",
@@ -282,7 +280,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		CfgBalancer: prometheus.NewGaugeVec(
+		CfgBalancer: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_balancer_status",
@@ -291,7 +289,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		CfgBalancerThreshold: prometheus.NewGaugeVec(
+		CfgBalancerThreshold: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_cfg_balancer_threshold",
@@ -300,7 +298,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatBalancerRunning: prometheus.NewGaugeVec(
+		SumStatBalancerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_balancer_running",
@@ -309,7 +307,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatDrainerRunning: prometheus.NewGaugeVec(
+		SumStatDrainerRunning: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_drainer_running",
@@ -318,7 +316,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatDiskIopsConfigstatusRw: prometheus.NewGaugeVec(
+		SumStatDiskIopsConfigstatusRw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_disk_iops_configrw",
@@ -327,7 +325,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatDiskBwConfigstatusRw: prometheus.NewGaugeVec(
+		SumStatDiskBwConfigstatusRw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_disk_bw_configrw",
@@ -336,7 +334,7 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 			},
 			[]string{"space"},
 		),
-		SumStatStatfsFreebytesConfigstatusRw: prometheus.NewGaugeVec(
+		SumStatStatfsFreebytesConfigstatusRw: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "space_statfs_freebytes_configrw",
@@ -348,10 +346,12 @@ func NewSpaceCollector(opts *CollectorOpts) *SpaceCollector {
 
 		// SumStatStatfsFreebytesConfigstatusRw
 	}
+	o.batchCollector = batchCollector{what: "space metrics", collect: o.collectSpaceDF}
+	return o
 }
 
-func (o *SpaceCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *SpaceCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.CfgGroupSize,
 		o.CfgGroupMod,
 		o.Nofs,
@@ -384,212 +384,75 @@ func (o *SpaceCollector) collectorList() []prometheus.Collector {
 	}
 }
 
-func (o *SpaceCollector) collectSpaceDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *SpaceCollector) collectSpaceDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	mds, err := client.ListSpace(context.Background(), "root")
+	mds, err := client.ListSpace(ctx, "root")
 	if err != nil {
 		return err
 	}
 
-	// reset gauges (to drop metrics of deleted spaces)
-
-	o.CfgGroupSize.Reset()
-	o.CfgGroupMod.Reset()
-	o.Nofs.Reset()
-	o.AvgStatDiskLoad.Reset()
-	o.SigStatDiskLoad.Reset()
-	o.SumStatDiskReadratemb.Reset()
-	o.SumStatDiskWriteratemb.Reset()
-	o.SumStatNetEthratemib.Reset()
-	o.SumStatNetInratemib.Reset()
-	o.SumStatNetOutratemib.Reset()
-	o.SumStatRopen.Reset()
-	o.SumStatWopen.Reset()
-	o.SumStatStatfsUsedbytes.Reset()
-	o.SumStatStatfsFreebytes.Reset()
-	o.SumStatStatfsCapacity.Reset()
-	o.SumStatUsedfiles.Reset()
-	o.SumStatStatfsFfiles.Reset()
-	o.SumStatStatfsFiles.Reset()
-	o.SumStatStatfsCapacityConfigstatusRw.Reset()
-	o.SumNofsConfigstatusRw.Reset()
-	o.CfgQuota.Reset()
-	o.CfgNominalsize.Reset()
-	o.CfgBalancer.Reset()
-	o.CfgBalancerThreshold.Reset()
-	o.SumStatBalancerRunning.Reset()
-	o.SumStatDrainerRunning.Reset()
-	o.SumStatDiskIopsConfigstatusRw.Reset()
-	o.SumStatDiskBwConfigstatusRw.Reset()
-	o.SumStatStatfsFreebytesConfigstatusRw.Reset()
-
 	for _, m := range mds {
 
-		nofs, err := strconv.ParseFloat(m.Nofs, 64)
-		if err == nil {
-			o.Nofs.WithLabelValues(m.Name).Set(nofs)
-		}
-
-		avgdl, err := strconv.ParseFloat(m.AvgStatDiskLoad, 64)
-		if err == nil {
-			o.AvgStatDiskLoad.WithLabelValues(m.Name).Set(avgdl)
-		}
-
-		sigdl, err := strconv.ParseFloat(m.SigStatDiskLoad, 64)
-		if err == nil {
-			o.SigStatDiskLoad.WithLabelValues(m.Name).Set(sigdl)
-		}
-
-		sumdiskr, err := strconv.ParseFloat(m.SumStatDiskReadratemb, 64)
-		if err == nil {
-			o.SumStatDiskReadratemb.WithLabelValues(m.Name).Set(sumdiskr)
-		}
-
-		sumdiskw, err := strconv.ParseFloat(m.SumStatDiskWriteratemb, 64)
-		if err == nil {
-			o.SumStatDiskWriteratemb.WithLabelValues(m.Name).Set(sumdiskw)
-		}
-
-		sumethrate, err := strconv.ParseFloat(m.SumStatNetEthratemib, 64)
-		if err == nil {
-			o.SumStatNetEthratemib.WithLabelValues(m.Name).Set(sumethrate)
-		}
-
-		suminrate, err := strconv.ParseFloat(m.SumStatNetInratemib, 64)
-		if err == nil {
-			o.SumStatNetInratemib.WithLabelValues(m.Name).Set(suminrate)
-		}
-
-		sumoutrate, err := strconv.ParseFloat(m.SumStatNetOutratemib, 64)
-		if err == nil {
-			o.SumStatNetOutratemib.WithLabelValues(m.Name).Set(sumoutrate)
-		}
-
-		ropen, err := strconv.ParseFloat(m.SumStatRopen, 64)
-		if err == nil {
-			o.SumStatRopen.WithLabelValues(m.Name).Set(ropen)
-		}
-
-		wopen, err := strconv.ParseFloat(m.SumStatWopen, 64)
-		if err == nil {
-			o.SumStatWopen.WithLabelValues(m.Name).Set(wopen)
-		}
-
-		usedb, err := strconv.ParseFloat(m.SumStatStatfsUsedbytes, 64)
-		if err == nil {
-			o.SumStatStatfsUsedbytes.WithLabelValues(m.Name).Set(usedb)
-		}
-
-		fbytes, err := strconv.ParseFloat(m.SumStatStatfsFreebytes, 64)
-		if err == nil {
-			o.SumStatStatfsFreebytes.WithLabelValues(m.Name).Set(fbytes)
-		}
-
-		fscap, err := strconv.ParseFloat(m.SumStatStatfsCapacity, 64)
-		if err == nil {
-			o.SumStatStatfsCapacity.WithLabelValues(m.Name).Set(fscap)
-		}
-
-		ufiles, err := strconv.ParseFloat(m.SumStatUsedfiles, 64)
-		if err == nil {
-			o.SumStatUsedfiles.WithLabelValues(m.Name).Set(ufiles)
-		}
-
-		files, err := strconv.ParseFloat(m.SumStatStatfsFiles, 64)
-		if err == nil {
-			o.SumStatStatfsFiles.WithLabelValues(m.Name).Set(files)
-		}
-
-		caprw, err := strconv.ParseFloat(m.SumStatStatfsCapacityConfigstatusRw, 64)
-		if err == nil {
-			o.SumStatStatfsCapacityConfigstatusRw.WithLabelValues(m.Name).Set(caprw)
-		}
-
-		nofsrw, err := strconv.ParseFloat(m.SumNofsConfigstatusRw, 64)
-		if err == nil {
-			o.SumNofsConfigstatusRw.WithLabelValues(m.Name).Set(nofsrw)
-		}
-
-		balr, err := strconv.ParseFloat(m.SumStatBalancerRunning, 64)
-		if err == nil {
-			o.SumStatBalancerRunning.WithLabelValues(m.Name).Set(balr)
-		}
-
-		drainr, err := strconv.ParseFloat(m.SumStatDrainerRunning, 64)
-		if err == nil {
-			o.SumStatDrainerRunning.WithLabelValues(m.Name).Set(drainr)
-		}
-
-		iopsrw, err := strconv.ParseFloat(m.SumStatDiskIopsConfigstatusRw, 64)
-		if err == nil {
-			o.SumStatDiskIopsConfigstatusRw.WithLabelValues(m.Name).Set(iopsrw)
-		}
-
-		bwrw, err := strconv.ParseFloat(m.SumStatDiskBwConfigstatusRw, 64)
-		if err == nil {
-			o.SumStatDiskBwConfigstatusRw.WithLabelValues(m.Name).Set(bwrw)
-		}
+		setValue(b, o.Nofs, m.Nofs, m.Name)
 
-		// Balancer Status
+		setValue(b, o.AvgStatDiskLoad, m.AvgStatDiskLoad, m.Name)
+
+		setValue(b, o.SigStatDiskLoad, m.SigStatDiskLoad, m.Name)
+
+		setValue(b, o.SumStatDiskReadratemb, m.SumStatDiskReadratemb, m.Name)
+
+		setValue(b, o.SumStatDiskWriteratemb, m.SumStatDiskWriteratemb, m.Name)
+
+		setValue(b, o.SumStatNetEthratemib, m.SumStatNetEthratemib, m.Name)
+
+		setValue(b, o.SumStatNetInratemib, m.SumStatNetInratemib, m.Name)
+
+		setValue(b, o.SumStatNetOutratemib, m.SumStatNetOutratemib, m.Name)
+
+		setValue(b, o.SumStatRopen, m.SumStatRopen, m.Name)
+
+		setValue(b, o.SumStatWopen, m.SumStatWopen, m.Name)
+
+		setValue(b, o.SumStatStatfsUsedbytes, m.SumStatStatfsUsedbytes, m.Name)
+
+		setValue(b, o.SumStatStatfsFreebytes, m.SumStatStatfsFreebytes, m.Name)
+
+		setValue(b, o.SumStatStatfsCapacity, m.SumStatStatfsCapacity, m.Name)
+
+		setValue(b, o.SumStatUsedfiles, m.SumStatUsedfiles, m.Name)
+
+		setValue(b, o.SumStatStatfsFiles, m.SumStatStatfsFiles, m.Name)
 
-		balancer_status := 0
-		switch stat := m.CfgBalancer; stat {
-		case "on":
-			balancer_status = 1
-		default:
-			balancer_status = 0
-		}
+		setValue(b, o.SumStatStatfsCapacityConfigstatusRw, m.SumStatStatfsCapacityConfigstatusRw, m.Name)
 
-		o.CfgBalancer.WithLabelValues(m.Name).Set(float64(balancer_status))
+		setValue(b, o.SumNofsConfigstatusRw, m.SumNofsConfigstatusRw, m.Name)
 
-		balt, err := strconv.ParseFloat(m.CfgBalancerThreshold, 64)
-		if err == nil {
-			o.CfgBalancerThreshold.WithLabelValues(m.Name).Set(balt)
-		}
+		setValue(b, o.SumStatBalancerRunning, m.SumStatBalancerRunning, m.Name)
 
-		gsize, err := strconv.ParseFloat(m.CfgGroupSize, 64)
-		if err == nil {
-			o.CfgGroupSize.WithLabelValues(m.Name).Set(gsize)
-		}
+		setValue(b, o.SumStatDrainerRunning, m.SumStatDrainerRunning, m.Name)
 
-		gmod, err := strconv.ParseFloat(m.CfgGroupMod, 64)
-		if err == nil {
-			o.CfgGroupMod.WithLabelValues(m.Name).Set(gmod)
-		}
+		setValue(b, o.SumStatDiskIopsConfigstatusRw, m.SumStatDiskIopsConfigstatusRw, m.Name)
+
+		setValue(b, o.SumStatDiskBwConfigstatusRw, m.SumStatDiskBwConfigstatusRw, m.Name)
+
+		// Balancer Status
+
+		b.set(o.CfgBalancer, boolValue(m.CfgBalancer), m.Name)
+
+		setValue(b, o.CfgBalancerThreshold, m.CfgBalancerThreshold, m.Name)
+
+		setValue(b, o.CfgGroupSize, m.CfgGroupSize, m.Name)
+
+		setValue(b, o.CfgGroupMod, m.CfgGroupMod, m.Name)
 
 		// Quota Status
 
-		quota_status := 0
-		switch stat := m.CfgQuota; stat {
-		case "on":
-			quota_status = 1
-		default:
-			quota_status = 0
-		}
-
-		o.CfgQuota.WithLabelValues(m.Name).Set(float64(quota_status))
-
-		// convert nominal size 0 if not defined.
-		if m.CfgNominalsize != "???" {
-			nomsize, err := strconv.ParseFloat(m.CfgNominalsize, 64)
-			if err == nil {
-				o.CfgNominalsize.WithLabelValues(m.Name).Set(nomsize)
-			}
-		} else {
-			o.CfgNominalsize.WithLabelValues(m.Name).Set(0)
-		}
-
-		fbytesRW, err := strconv.ParseFloat(m.SumStatStatfsFreebytesConfigstatusRw, 64)
-		if err == nil {
-			o.SumStatStatfsFreebytesConfigstatusRw.WithLabelValues(m.Name).Set(fbytesRW)
-		}
+		b.set(o.CfgQuota, boolValue(m.CfgQuota), m.Name)
+
+		setValue(b, o.CfgNominalsize, m.CfgNominalsize, m.Name)
+
+		setValue(b, o.SumStatStatfsFreebytesConfigstatusRw, m.SumStatStatfsFreebytesConfigstatusRw, m.Name)
 
 	}
 
@@ -603,16 +466,3 @@ func (o *SpaceCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *SpaceCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectSpaceDF(); err != nil {
-		log.Println("failed collecting space metrics:", err)
-		return
-	}
-
-	for _, metric := range o.collectorList() {
-		metric.Collect(ch)
-	}
-}
@@ -2,11 +2,9 @@ package collector
 
 import (
 	"context"
-	"log"
 	"os"
 	"strings"
 
-	"github.com/cern-eos/eos_exporter/eosclient"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -25,7 +23,8 @@ import (
 
 type WhoCollector struct {
 	*CollectorOpts
-	SessionNumber *prometheus.GaugeVec
+	batchCollector
+	SessionNumber *metricVec
 	file          *os.File
 }
 
@@ -42,10 +41,10 @@ func NewWhoCollector(opts *CollectorOpts) *WhoCollector {
 	//	panic(err)
 	//}
 
-	return &WhoCollector{
+	o := &WhoCollector{
 		//file: f,
 		CollectorOpts: opts,
-		SessionNumber: prometheus.NewGaugeVec(
+		SessionNumber: newGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace:   namespace,
 				Name:        "who",
@@ -55,24 +54,20 @@ func NewWhoCollector(opts *CollectorOpts) *WhoCollector {
 			[]string{"uid", "auth", "gateway", "app"},
 		),
 	}
+	o.batchCollector = batchCollector{what: "who metrics", collect: o.collectWhoDF}
+	return o
 }
 
-func (o *WhoCollector) collectorList() []prometheus.Collector {
-	return []prometheus.Collector{
+func (o *WhoCollector) collectorList() []*metricVec {
+	return []*metricVec{
 		o.SessionNumber,
 	}
 }
 
-func (o *WhoCollector) collectWhoDF() error {
-	ins := getEOSInstance()
-	url := "root://" + ins
-	opt := &eosclient.Options{URL: url, Timeout: o.Timeout}
-	client, err := eosclient.New(opt)
-	if err != nil {
-		panic(err)
-	}
+func (o *WhoCollector) collectWhoDF(ctx context.Context, b *metricBatch) error {
+	client := o.Client
 
-	whos, err := client.Who(context.Background(), "root")
+	whos, err := client.Who(ctx, "root")
 	if err != nil {
 		return err
 	}
@@ -90,16 +85,10 @@ func (o *WhoCollector) collectWhoDF() error {
 		}
 	}
 
-	// a GaugeVector keeps its state for any combination of labels until the process is restarted.
-	// For metrics obtained from systems like EOS that do not produce a complete set of metrics (only the active metrics)
-	// then we risk to expose these metrics forever until the next process restart.
-	// To workaround this, we reset the gauge vectors when collecting metrics.
-
-	o.SessionNumber.Reset()
 	for i, v := range counter {
 		tokens := strings.Split(i, ":::")
 		uid, auth, gateway, app := tokens[0], tokens[1], tokens[2], tokens[3]
-		o.SessionNumber.WithLabelValues(uid, auth, gateway, app).Set(float64(v))
+		b.set(o.SessionNumber, float64(v), uid, auth, gateway, app)
 		//o.file.WriteString(fmt.Sprintf("%s setting (%s)=%d\n", t, i, v))
 	}
 
@@ -113,16 +102,3 @@ func (o *WhoCollector) Describe(ch chan<- *prometheus.Desc) {
 		metric.Describe(ch)
 	}
 }
-
-// Collect sends all the collected metrics to the provided prometheus channel.
-func (o *WhoCollector) Collect(ch chan<- prometheus.Metric) {
-
-	if err := o.collectWhoDF(); err != nil {
-		log.Println("failed collecting who  metrics:", err)
-		return
-	}
-
-	for _, collector := range o.collectorList() {
-		collector.Collect(ch)
-	}
-}
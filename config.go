@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cern-eos/eos_exporter/collector"
+)
+
+const (
+	endpointStandard = "standard"
+	endpointFast     = "fast"
+)
+
+// Fast metrics will not be exposed in the standard endpoint to avoid duplication!
+var fastCollectorsSet = map[string]bool{
+	"traffic_shaping_io":     true,
+	"traffic_shaping_policy": true,
+	// Add future fast metrics here
+}
+
+// Config is the content of the --config-file. Anything left out falls back to the flags.
+// The timeouts bound the runs of the collectors, while --timeout still bounds each eos command.
+type Config struct {
+	Timeout           time.Duration              `yaml:"timeout"` // default timeout of the collectors
+	AuditLogPath      string                     `yaml:"audit_log_path"`
+	AuditPollInterval time.Duration              `yaml:"audit_poll_interval"`
+	Collectors        map[string]CollectorConfig `yaml:"collectors"`
+	Endpoints         []EndpointConfig           `yaml:"endpoints"`
+	Modules           map[string]ModuleConfig    `yaml:"modules"`
+	Instances         []InstanceConfig           `yaml:"instances"`
+}
+
+// ModuleConfig is a set of collectors run by the /probe endpoint against an instance
+type ModuleConfig struct {
+	Collectors []string      `yaml:"collectors"`
+	Timeout    time.Duration `yaml:"timeout"` // overrides the timeouts of the collectors
+}
+
+// InstanceConfig is an EOS instance the /probe endpoint may target
+type InstanceConfig struct {
+	Name string `yaml:"name"` // cluster label of the metrics
+	URL  string `yaml:"url"`  // MGM URL, e.g. root://eos-example.cern.ch
+}
+
+// EndpointConfig is a metrics path serving its own set of collectors. The collectors
+// of the endpoints are left out of the standard and fast endpoints.
+type EndpointConfig struct {
+	Name          string   `yaml:"name"`
+	ListenAddress string   `yaml:"listen_address"` // defaults to --listen-address
+	Path          string   `yaml:"path"`
+	Collectors    []string `yaml:"collectors"`
+}
+
+// CollectorConfig holds the settings of one of the availableCollectors
+type CollectorConfig struct {
+	Enabled     *bool         `yaml:"enabled"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Interval    time.Duration `yaml:"interval"`     // background refresh interval
+	StalePolicy string        `yaml:"stale_policy"` // drop, forever or a duration
+	Labels      LabelsConfig  `yaml:"labels"`
+}
+
+// LabelsConfig filters the series of a collector by label value. The expressions
+// are anchored: a series is kept if every allow expression matches the value of
+// its label and no deny expression does.
+type LabelsConfig struct {
+	Allow map[string]string `yaml:"allow"`
+	Deny  map[string]string `yaml:"deny"`
+}
+
+// loadConfig reads and validates a configuration file
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Timeout < 0 || cfg.AuditPollInterval < 0 {
+		return fmt.Errorf("durations must be positive")
+	}
+
+	known := make(map[string]bool, len(availableCollectors))
+	for _, c := range availableCollectors {
+		known[c.name] = true
+	}
+
+	for name, cc := range cfg.Collectors {
+		if !known[name] {
+			return fmt.Errorf("unknown collector %q", name)
+		}
+		if cc.Timeout < 0 || cc.Interval < 0 {
+			return fmt.Errorf("collector %s: durations must be positive", name)
+		}
+		if cc.StalePolicy != "" {
+			if _, err := parseStalePolicy(cc.StalePolicy); err != nil {
+				return fmt.Errorf("collector %s: %w", name, err)
+			}
+		}
+		if _, err := newLabelFilter(cc.Labels); err != nil {
+			return fmt.Errorf("collector %s: %w", name, err)
+		}
+	}
+
+	names := map[string]bool{endpointStandard: true, endpointFast: true}
+	for _, ep := range cfg.Endpoints {
+		if ep.Name == "" || names[ep.Name] {
+			return fmt.Errorf("endpoint names must be unique and other than %s and %s, got %q", endpointStandard, endpointFast, ep.Name)
+		}
+		names[ep.Name] = true
+	}
+
+	for name, module := range cfg.Modules {
+		if module.Timeout < 0 {
+			return fmt.Errorf("module %s: timeout must be positive", name)
+		}
+		for _, c := range module.Collectors {
+			if !known[c] || c == "audit" {
+				return fmt.Errorf("module %s: collector %q can not be probed", name, c)
+			}
+		}
+	}
+
+	instances := make(map[string]bool)
+	for _, instance := range cfg.Instances {
+		if instance.Name == "" || instance.URL == "" {
+			return fmt.Errorf("instances need a name and an url")
+		}
+		if instances[instance.Name] || instances[instance.URL] {
+			return fmt.Errorf("duplicate instance %s", instance.Name)
+		}
+		instances[instance.Name] = true
+		instances[instance.URL] = true
+	}
+
+	paths := make(map[string]bool)
+	for _, ep := range cfg.endpoints() {
+		if !strings.HasPrefix(ep.Path, "/") {
+			return fmt.Errorf("endpoint %s: path must start with /, got %q", ep.Name, ep.Path)
+		}
+		if reservedPaths[ep.Path] {
+			return fmt.Errorf("endpoint %s: path %s is reserved", ep.Name, ep.Path)
+		}
+		if paths[ep.ListenAddress+ep.Path] {
+			return fmt.Errorf("endpoint %s: %s%s is already used by another endpoint", ep.Name, ep.ListenAddress, ep.Path)
+		}
+		paths[ep.ListenAddress+ep.Path] = true
+		for _, name := range ep.Collectors {
+			if !known[name] {
+				return fmt.Errorf("endpoint %s: unknown collector %q", ep.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// endpoints returns the standard endpoint, the fast one when enabled, and the
+// endpoints of the configuration with their default listen address
+func (cfg *Config) endpoints() []EndpointConfig {
+	endpoints := []EndpointConfig{{Name: endpointStandard, ListenAddress: cmdOptions.ListenAddress, Path: cmdOptions.MetricsPath}}
+	if cmdOptions.EnableFastExporter {
+		endpoints = append(endpoints, EndpointConfig{Name: endpointFast, ListenAddress: cmdOptions.ListenAddressFast, Path: cmdOptions.MetricsPath})
+	}
+	for _, ep := range cfg.Endpoints {
+		if ep.ListenAddress == "" {
+			ep.ListenAddress = cmdOptions.ListenAddress
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// collectorSpecs resolves the collectors to run on each endpoint from the flags
+// and the configuration file
+func collectorSpecs(cfg *Config, base collector.CollectorOpts) map[string][]collectorSpec {
+	assigned := make(map[string][]string)
+	for _, ep := range cfg.Endpoints {
+		for _, name := range ep.Collectors {
+			assigned[name] = append(assigned[name], ep.Name)
+		}
+	}
+
+	requestedMap := make(map[string]bool)
+	if cmdOptions.Collectors != "all" && cmdOptions.Collectors != "" {
+		for _, r := range strings.Split(cmdOptions.Collectors, ",") {
+			requestedMap[strings.TrimSpace(r)] = true
+		}
+	}
+
+	opts := cfg.collectorOpts(base)
+	specs := make(map[string][]collectorSpec)
+	for _, c := range availableCollectors {
+		cc := cfg.Collectors[c.name]
+
+		endpoints := assigned[c.name]
+		// Collectors listed by an endpoint are enabled, fast collectors are enabled with
+		// the fast endpoint and the others obey the --collectors flag. The enabled setting
+		// of the configuration overrides them: a fast collector enabled without the fast
+		// endpoint runs on the standard one.
+		enabled := len(endpoints) > 0 || cmdOptions.Collectors == "all" || cmdOptions.Collectors == "" || requestedMap[c.name]
+		if len(endpoints) == 0 {
+			if fastCollectorsSet[c.name] {
+				endpoints = []string{endpointFast}
+				enabled = cmdOptions.EnableFastExporter
+			} else {
+				endpoints = []string{endpointStandard}
+			}
+		}
+		if cc.Enabled != nil {
+			enabled = *cc.Enabled
+			if enabled && endpoints[0] == endpointFast && !cmdOptions.EnableFastExporter {
+				endpoints = []string{endpointStandard}
+			}
+		}
+		if !enabled {
+			continue
+		}
+
+		for _, endpoint := range endpoints {
+			specs[endpoint] = append(specs[endpoint], collectorSpec{name: c.name, creator: c.creator, opts: opts, settings: cfg.settings(c.name)})
+		}
+	}
+	return specs
+}
+
+// collectorOpts returns the base options with the overrides of the configuration
+func (cfg *Config) collectorOpts(base collector.CollectorOpts) collector.CollectorOpts {
+	if cfg.AuditLogPath != "" {
+		base.AuditLogPath = cfg.AuditLogPath
+	}
+	if cfg.AuditPollInterval > 0 {
+		base.AuditPollInterval = int(math.Ceil(cfg.AuditPollInterval.Seconds()))
+	}
+	return base
+}
+
+// settings resolves the settings of a collector from the flags and the configuration
+func (cfg *Config) settings(name string) collectorSettings {
+	intervals, _ := parseDurations(cmdOptions.PollIntervals)
+	stalePolicies, _ := parseStalePolicies(cmdOptions.StalePolicies)
+
+	settings := collectorSettings{
+		Timeout:  time.Duration(cmdOptions.Timeout) * time.Second,
+		Interval: pollInterval(name, intervals),
+	}
+	settings.Stale, _ = parseStalePolicy(cmdOptions.StalePolicy)
+	if policy, ok := stalePolicies[name]; ok {
+		settings.Stale = policy
+	}
+	if cfg.Timeout > 0 {
+		settings.Timeout = cfg.Timeout
+	}
+
+	cc := cfg.Collectors[name]
+	if cc.Timeout > 0 {
+		settings.Timeout = cc.Timeout
+	}
+	if cc.Interval > 0 {
+		settings.Interval = cc.Interval
+	}
+	if cc.StalePolicy != "" {
+		settings.Stale, _ = parseStalePolicy(cc.StalePolicy)
+	}
+	settings.Filter, _ = newLabelFilter(cc.Labels)
+	return settings
+}
+
+// labelFilter keeps the series of a collector according to its LabelsConfig
+type labelFilter struct {
+	cfg   LabelsConfig
+	allow map[string]*regexp.Regexp
+	deny  map[string]*regexp.Regexp
+}
+
+// newLabelFilter compiles the expressions of cfg. It returns a nil filter,
+// keeping every series, when cfg is empty.
+func newLabelFilter(cfg LabelsConfig) (*labelFilter, error) {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil, nil
+	}
+
+	compile := func(exprs map[string]string) (map[string]*regexp.Regexp, error) {
+		res := make(map[string]*regexp.Regexp, len(exprs))
+		for label, expr := range exprs {
+			re, err := regexp.Compile("^(?:" + expr + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("label %s: %w", label, err)
+			}
+			res[label] = re
+		}
+		return res, nil
+	}
+
+	allow, err := compile(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := compile(cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &labelFilter{cfg: cfg, allow: allow, deny: deny}, nil
+}
+
+// equal reports whether both filters keep the same series
+func (f *labelFilter) equal(other *labelFilter) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return reflect.DeepEqual(f.cfg, other.cfg)
+}
+
+// keep reports whether the metric passes the filter. A missing label has an empty value.
+func (f *labelFilter) keep(metric prometheus.Metric) bool {
+	if f == nil {
+		return true
+	}
+
+	pb := &dto.Metric{}
+	if err := metric.Write(pb); err != nil {
+		return false
+	}
+	values := make(map[string]string, len(pb.Label))
+	for _, pair := range pb.Label {
+		values[pair.GetName()] = pair.GetValue()
+	}
+
+	for label, re := range f.allow {
+		if !re.MatchString(values[label]) {
+			return false
+		}
+	}
+	for label, re := range f.deny {
+		if re.MatchString(values[label]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cern-eos/eos_exporter/collector"
+)
+
+// setOptions changes the flags for the duration of the test
+func setOptions(t *testing.T, set func(o *Options)) {
+	t.Helper()
+	saved := *cmdOptions
+	t.Cleanup(func() { *cmdOptions = saved })
+	set(cmdOptions)
+}
+
+// parseConfig validates a configuration like loadConfig
+func parseConfig(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return loadConfig(path)
+}
+
+func specNames(specs []collectorSpec) string {
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestCollectorSpecs(t *testing.T) {
+	setOptions(t, func(o *Options) {
+		o.Collectors = "fs,node"
+		o.EnableFastExporter = false
+	})
+	cfg, err := parseConfig(t, `
+collectors:
+  node:
+    enabled: false
+  who:
+    enabled: true
+  traffic_shaping_io:
+    enabled: true
+endpoints:
+  - name: inspector
+    path: /metrics/inspector
+    collectors: [inspector_layout]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	specs := collectorSpecs(cfg, collector.CollectorOpts{})
+	// fs from the flag, who and traffic_shaping_io enabled by the configuration,
+	// the latter on the standard endpoint as the fast one is disabled
+	if got := specNames(specs[endpointStandard]); got != "fs,traffic_shaping_io,who" {
+		t.Fatalf("unexpected standard collectors %s", got)
+	}
+	if got := specNames(specs["inspector"]); got != "inspector_layout" {
+		t.Fatalf("unexpected inspector collectors %s", got)
+	}
+	if len(specs[endpointFast]) != 0 {
+		t.Fatalf("unexpected fast collectors %s", specNames(specs[endpointFast]))
+	}
+}
+
+func TestCollectorSpecsFastEndpoint(t *testing.T) {
+	setOptions(t, func(o *Options) {
+		o.Collectors = "all"
+		o.EnableFastExporter = true
+	})
+
+	specs := collectorSpecs(&Config{}, collector.CollectorOpts{})
+	if got := specNames(specs[endpointFast]); got != "traffic_shaping_io,traffic_shaping_policy" {
+		t.Fatalf("unexpected fast collectors %s", got)
+	}
+	for _, spec := range specs[endpointStandard] {
+		if fastCollectorsSet[spec.name] {
+			t.Fatalf("fast collector %s on the standard endpoint", spec.name)
+		}
+	}
+}
+
+func TestSettings(t *testing.T) {
+	setOptions(t, func(o *Options) {
+		o.Timeout = 30
+		o.StalePolicy = "drop"
+		o.StalePolicies = "quotas=forever"
+		o.PollIntervals = "node=1m"
+	})
+	cfg, err := parseConfig(t, `
+timeout: 20s
+collectors:
+  fs:
+    timeout: 10s
+    interval: 30s
+    stale_policy: 10m
+    labels:
+      allow:
+        space: default
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := cfg.settings("fs")
+	if fs.Timeout != 10*time.Second || fs.Interval != 30*time.Second || fs.Stale != stalePolicy(10*time.Minute) || fs.Filter == nil {
+		t.Fatalf("unexpected fs settings %+v", fs)
+	}
+	node := cfg.settings("node")
+	if node.Timeout != 20*time.Second || node.Interval != time.Minute || node.Stale != staleDrop || node.Filter != nil {
+		t.Fatalf("unexpected node settings %+v", node)
+	}
+	if quotas := cfg.settings("quotas"); quotas.Stale != staleForever || quotas.Interval != 5*time.Minute {
+		t.Fatalf("unexpected quotas settings %+v", quotas)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown field":      "timeot: 10s",
+		"unknown collector":  "collectors: {nope: {enabled: true}}",
+		"invalid expression": "collectors: {fs: {labels: {allow: {space: '('}}}}",
+		"invalid policy":     "collectors: {fs: {stale_policy: sometimes}}",
+		"reserved path":      "endpoints: [{name: x, path: /-/ready, collectors: [fs]}]",
+		"standard path":      "endpoints: [{name: x, path: /metrics, collectors: [fs]}]",
+		"duplicate path":     "endpoints: [{name: x, path: /m, collectors: [fs]}, {name: y, path: /m, collectors: [node]}]",
+		"duplicate name":     "endpoints: [{name: x, path: /m, collectors: [fs]}, {name: x, path: /n, collectors: [node]}]",
+		"audit module":       "modules: {m: {collectors: [audit]}}",
+	} {
+		if _, err := parseConfig(t, content); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// The same path on another listener is fine
+	if _, err := parseConfig(t, "endpoints: [{name: x, listen_address: ':9999', path: /metrics, collectors: [fs]}]"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLabelFilter(t *testing.T) {
+	filter, err := newLabelFilter(LabelsConfig{
+		Allow: map[string]string{"space": "default|spare"},
+		Deny:  map[string]string{"node": "fst-test-.*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := prometheus.NewDesc("m", "m", []string{"space", "node"}, nil)
+	for _, tc := range []struct {
+		space, node string
+		keep        bool
+	}{
+		{"default", "fst-1", true},
+		{"spare", "fst-1", true},
+		{"defaults", "fst-1", false}, // anchored
+		{"default", "fst-test-1", false},
+	} {
+		metric := prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, tc.space, tc.node)
+		if got := filter.keep(metric); got != tc.keep {
+			t.Errorf("space=%s node=%s: expected keep=%v", tc.space, tc.node, tc.keep)
+		}
+	}
+}
+
+func TestSameLayout(t *testing.T) {
+	a := []EndpointConfig{{Name: "x", Path: "/x", Collectors: []string{"fs"}}}
+	b := []EndpointConfig{{Name: "x", Path: "/x", Collectors: []string{"node"}}}
+	if !sameLayout(a, b) {
+		t.Fatal("endpoints differing by their collectors only have the same layout")
+	}
+	b[0].Path = "/y"
+	if sameLayout(a, b) {
+		t.Fatal("endpoints with different paths do not have the same layout")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	layout := (&Config{Endpoints: []EndpointConfig{{Name: "spaces", Path: "/spaces", Collectors: []string{"space"}}}}).endpoints()
+	for _, tc := range []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"collectors moved", "endpoints:\n  - name: spaces\n    path: /spaces\n    collectors: [space, node]\n", ""},
+		{"endpoint added", "endpoints:\n  - name: spaces\n    path: /spaces\n  - name: nodes\n    path: /nodes\n", "endpoints changed"},
+		{"path changed", "endpoints:\n  - name: spaces\n    path: /space\n", "endpoints changed"},
+		{"invalid", "endpoints: [", "parsing"},
+	} {
+		cfg, err := reloadConfig(func() (*Config, error) { return parseConfig(t, tc.yaml) }, layout)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.err, err)
+		case tc.err != "" && cfg != nil:
+			t.Errorf("%s: expected no configuration with the error", tc.name)
+		}
+	}
+}
+
+func TestServerRoutes(t *testing.T) {
+	a := newFakeCollector("a")
+	exporter := newTestExporter(t, exporterOpts{Workers: 1}, collectorSettings{}, map[string]*fakeCollector{"a": a})
+	ep := &endpoint{EndpointConfig: EndpointConfig{Name: endpointStandard, Path: "/metrics"}, exporter: exporter, registry: prometheus.NewRegistry()}
+	server := createServer(":0", []*endpoint{ep}, map[string]http.Handler{
+		"/-/healthy": http.HandlerFunc(healthy),
+	})
+
+	for _, tc := range []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/metrics", http.StatusOK, "fake_a 0"},
+		{"/metrics?collect[]=a", http.StatusOK, "fake_a 0"},
+		{"/metrics?collect[]=b", http.StatusBadRequest, "unknown collector"},
+		{"/-/healthy", http.StatusOK, "OK"},
+	} {
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("%s: got %d %q", tc.url, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// debugEOS serves /debug/eos, the raw outputs of the last run of each eos command,
+// with the values of the redacted fields replaced, e.g. uids and client hostnames
+type debugEOS struct {
+	trace *eosclient.CommandTrace
+
+	// Redacted fields in the key=value, the key => value (e.g. vid ls) and the JSON
+	// outputs, nil when none
+	redactKV, redactArrow, redactJSON *regexp.Regexp
+}
+
+const (
+	// defaultDebugEOSRedact are the fields redacted by default: uids, and the client
+	// hostnames of who -a -m (client, gateway) and fusex ls -m (host)
+	defaultDebugEOSRedact = "uid,client,gateway,host"
+
+	// redactedValue replaces the values of the redacted fields
+	redactedValue = "REDACTED"
+)
+
+// newDebugEOS returns the handler of /debug/eos, redacting the comma-separated
+// fields in the key=value monitoring outputs, the key => value ones and the JSON ones
+func newDebugEOS(trace *eosclient.CommandTrace, fields string) *debugEOS {
+	var names []string
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			names = append(names, regexp.QuoteMeta(field))
+		}
+	}
+	d := &debugEOS{trace: trace}
+	if len(names) > 0 {
+		keys := strings.Join(names, "|")
+		// key=value or key="quoted value", the key not being the end of a longer one
+		d.redactKV = regexp.MustCompile(`((?:^|[\s&?])(?:` + keys + `)=)(?:"[^"]*"|[^\s&]*)`)
+		// e.g. krb5:"jdoe":uid => 1234
+		d.redactArrow = regexp.MustCompile(`((?:^|[\s:])(?:` + keys + `)\s*=>\s*)\S*`)
+		d.redactJSON = regexp.MustCompile(`("(?:` + keys + `)"\s*:\s*)(?:"[^"]*"|[^\s,}\]]*)`)
+	}
+	return d
+}
+
+// redacted returns s with the values of the redacted fields replaced
+func (d *debugEOS) redacted(s string) string {
+	if d.redactKV == nil {
+		return s
+	}
+	s = d.redactKV.ReplaceAllString(s, "${1}"+redactedValue)
+	s = d.redactArrow.ReplaceAllString(s, "${1}"+redactedValue)
+	return d.redactJSON.ReplaceAllString(s, `${1}"`+redactedValue+`"`)
+}
+
+func (d *debugEOS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	runs := d.trace.Runs()
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "# no eos command run yet")
+		return
+	}
+	for _, run := range runs {
+		fmt.Fprintf(w, "# %s\n", d.redacted(run.Command))
+		fmt.Fprintf(w, "# time: %s, duration: %s, exit code: %d\n", run.Time.Format(time.RFC3339), run.Duration, run.ExitCode)
+		if run.Err != nil {
+			fmt.Fprintf(w, "# error: %s\n", d.redacted(run.Err.Error()))
+		}
+		fmt.Fprintf(w, "## stdout\n%s\n", strings.TrimRight(d.redacted(run.Stdout), "\n"))
+		fmt.Fprintf(w, "## stderr\n%s\n\n", strings.TrimRight(d.redacted(run.Stderr), "\n"))
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+func TestDebugEOSRedaction(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		fields string
+		output string
+		want   string
+	}{
+		{
+			name:   "fusex ls -m",
+			fields: defaultDebugEOSRedact,
+			output: `_type=fusex client=eosxd host=lxplus801.cern.ch version=5.2.24 state=online time="Fri, 17 Oct 2026 12:00:00 GMT" tof=0.02 delta=0.50 uuid=7f1c3a9e-1d2b-11ee-b6f3-fa163e8b9c2d pid=31337 mount="/eos/user"
+_type=fusex client=eosxd host=b7g17p1234.cern.ch version=5.2.24 state=online time="Fri, 17 Oct 2026 12:00:01 GMT" tof=0.03 delta=0.41 uuid=0c2d9e1a-1d2b-11ee-b6f3-fa163e8b9c2d pid=4242 mount="/eos/project"`,
+			want: `_type=fusex client=REDACTED host=REDACTED version=5.2.24 state=online time="Fri, 17 Oct 2026 12:00:00 GMT" tof=0.02 delta=0.50 uuid=7f1c3a9e-1d2b-11ee-b6f3-fa163e8b9c2d pid=31337 mount="/eos/user"
+_type=fusex client=REDACTED host=REDACTED version=5.2.24 state=online time="Fri, 17 Oct 2026 12:00:01 GMT" tof=0.03 delta=0.41 uuid=0c2d9e1a-1d2b-11ee-b6f3-fa163e8b9c2d pid=4242 mount="/eos/project"`,
+		},
+		{
+			name:   "who -a -m",
+			fields: defaultDebugEOSRedact,
+			output: `uid=jdoe nsessions=2
+auth=krb5 nsessions=3
+client=jdoe@lxplus1.cern.ch uid=jdoe auth=https idle=66 gateway="gw.cern.ch" app=http
+client=x@b7.cern.ch uid=alice auth=krb5 idle=1 gateway="" app=fuse`,
+			want: `uid=REDACTED nsessions=2
+auth=krb5 nsessions=3
+client=REDACTED uid=REDACTED auth=https idle=66 gateway=REDACTED app=http
+client=REDACTED uid=REDACTED auth=krb5 idle=1 gateway=REDACTED app=fuse`,
+		},
+		{
+			name:   "vid ls",
+			fields: defaultDebugEOSRedact,
+			output: `krb5:"jdoe":uid => 1234
+krb5:"jdoe":gid => 1000
+publicaccesslevel: => 1024
+sudoer                 => uids(adm,daemon,root)`,
+			want: `krb5:"jdoe":uid => REDACTED
+krb5:"jdoe":gid => 1000
+publicaccesslevel: => 1024
+sudoer                 => uids(adm,daemon,root)`,
+		},
+		{
+			name:   "fs ls -m",
+			fields: defaultDebugEOSRedact,
+			output: `host=fst-1.cern.ch port=1095 id=12 path=/data01 stat.boot=booted stat.geotag=site::rack1`,
+			want:   `host=REDACTED port=1095 id=12 path=/data01 stat.boot=booted stat.geotag=site::rack1`,
+		},
+		{
+			name:   "longer keys are kept",
+			fields: defaultDebugEOSRedact,
+			output: `ruid=5 xhost=a stat.host=b uids=7`,
+			want:   `ruid=5 xhost=a stat.host=b uids=7`,
+		},
+		{
+			name:   "json",
+			fields: defaultDebugEOSRedact,
+			output: `[{"uid": 1234, "client": "jdoe@lxplus1.cern.ch", "app": "fuse"}]`,
+			want:   `[{"uid": "REDACTED", "client": "REDACTED", "app": "fuse"}]`,
+		},
+		{
+			name:   "no redaction",
+			fields: "",
+			output: `client=jdoe@lxplus1.cern.ch uid=jdoe`,
+			want:   `client=jdoe@lxplus1.cern.ch uid=jdoe`,
+		},
+	} {
+		if got := newDebugEOS(nil, tc.fields).redacted(tc.output); got != tc.want {
+			t.Errorf("%s: got\n%s\nexpected\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// outputExecutor answers every eos command with the same output
+type outputExecutor string
+
+func (e outputExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	return string(e), "", nil
+}
+
+func TestDebugEOSServesRedactedRuns(t *testing.T) {
+	trace := eosclient.NewCommandTrace()
+	debug := newDebugEOS(trace, defaultDebugEOSRedact)
+
+	rec := httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/eos", nil))
+	if !strings.Contains(rec.Body.String(), "no eos command run yet") {
+		t.Fatalf("unexpected page without runs: %q", rec.Body.String())
+	}
+
+	client, err := eosclient.New(&eosclient.Options{
+		URL:      "root://eos-example.cern.ch",
+		Executor: outputExecutor("_type=fusex client=eosxd host=lxplus801.cern.ch version=5.2.24 state=online\n"),
+		Trace:    trace,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListFusex(context.Background(), "root"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = httptest.NewRecorder()
+	debug.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/eos", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "fusex ls -m") || !strings.Contains(body, "host=REDACTED version=5.2.24") {
+		t.Fatalf("expected the redacted fusex ls -m run, got:\n%s", body)
+	}
+	if strings.Contains(body, "lxplus801") {
+		t.Fatalf("client hostname served:\n%s", body)
+	}
+}
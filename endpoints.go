@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// endpoint serves the metrics of its own EOSExporter on a path of a listener
+type endpoint struct {
+	EndpointConfig
+	exporter *EOSExporter
+	registry *prometheus.Registry // metrics served next to the ones of the exporter
+}
+
+// scrapeTimeoutOffset is left to send the response before Prometheus gives up on a scrape
+const scrapeTimeoutOffset = 500 * time.Millisecond
+
+// scrapeContext returns the context of a scrape, done when the request is abandoned
+// or when Prometheus gives up on it according to the X-Prometheus-Scrape-Timeout-Seconds header
+func scrapeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	seconds, err := strconv.ParseFloat(r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || seconds <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > 2*scrapeTimeoutOffset {
+		timeout -= scrapeTimeoutOffset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// serveView serves the metrics of the exporter bound to the context of the
+// request, along with the metrics of the gatherer if any. Like in the node_exporter,
+// the collect[] and exclude[] query parameters restrict the request to some of the
+// collectors.
+func serveView(w http.ResponseWriter, r *http.Request, exporter *EOSExporter, gatherer prometheus.Gatherer) {
+	ctx, cancel := scrapeContext(r)
+	defer cancel()
+
+	query := r.URL.Query()
+	view, err := exporter.View(ctx, query["collect[]"], query["exclude[]"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The view is bound to the request, so it goes in a registry of its own
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(view); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	gatherers := prometheus.Gatherers{registry}
+	if gatherer != nil {
+		gatherers = append(gatherers, gatherer)
+	}
+	promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// metricsHandler serves the metrics of the endpoint: its exporter and the other metrics of its registry
+func (ep *endpoint) metricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveView(w, r, ep.exporter, ep.registry)
+	})
+}
+
+// sameLayout reports whether the endpoints of two configurations have the same
+// names, listen addresses and paths, i.e. whether they can be served by the same listeners
+func sameLayout(a, b []EndpointConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.Collectors, y.Collectors = nil, nil
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// reloadConfig loads the configuration again. The listeners of the endpoints are set
+// up at startup, so a configuration changing their layout is rejected.
+func reloadConfig(load func() (*Config, error), layout []EndpointConfig) (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if !sameLayout(layout, cfg.endpoints()) {
+		return nil, errors.New("endpoints changed, restart the exporter to apply them")
+	}
+	return cfg, nil
+}
+
+// reservedPaths are served by the listeners next to the metrics of the endpoints
+var reservedPaths = map[string]bool{
+	"/":           true,
+	"/-/healthy":  true,
+	"/-/ready":    true,
+	"/api/status": true,
+	"/probe":      true,
+	"/debug/eos":  true,
+}
+
+// createServer builds an HTTP server for the endpoints sharing a listen address,
+// each endpoint with its own registry to isolate the metrics paths cleanly.
+// The handlers, e.g. the status page on "/", are served next to them.
+func createServer(address string, endpoints []*endpoint, handlers map[string]http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	for _, ep := range endpoints {
+		mux.Handle(ep.Path, ep.metricsHandler())
+	}
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+	return &http.Server{Addr: address, Handler: mux}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"flag"
@@ -28,11 +29,13 @@ import (
 	"syscall"
 	"time"
 
+	kitlog "github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors" // <-- New Import
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/exporter-toolkit/web"
 
 	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
 
 	_ "embed"
 )
@@ -52,76 +55,53 @@ var (
 // List of all available collectors and their constructor functions wrapped to return the interface
 var availableCollectors = []struct {
 	name    string
-	creator func(*collector.CollectorOpts) prometheus.Collector
+	creator func(*collector.CollectorOpts) collector.Collector
 }{
-	{"space", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewSpaceCollector(opts) }},
-	{"group", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewGroupCollector(opts) }},
-	{"node", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewNodeCollector(opts) }},
-	{"fs", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewFSCollector(opts) }},
-	{"io_info", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewIOInfoCollector(opts) }},
-	{"io_app_info", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewIOAppInfoCollector(opts) }},
-	{"traffic_shaping_io", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewIOShapingCollector(opts) }},
-	{"traffic_shaping_policy", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"space", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewSpaceCollector(opts) }},
+	{"group", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewGroupCollector(opts) }},
+	{"node", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewNodeCollector(opts) }},
+	{"fs", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewFSCollector(opts) }},
+	{"io_info", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewIOInfoCollector(opts) }},
+	{"io_app_info", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewIOAppInfoCollector(opts) }},
+	{"traffic_shaping_io", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewIOShapingCollector(opts) }},
+	{"traffic_shaping_policy", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewIOShapingPolicyCollector(opts)
 	}},
-	{"traffic_shaping_config", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"traffic_shaping_config", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewIOShapingConfigCollector(opts)
 	}},
-	{"ns", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewNSCollector(opts) }},
-	{"ns_activity", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"ns", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewNSCollector(opts) }},
+	{"ns_activity", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewNSActivityCollector(opts)
 	}},
-	{"ns_batch", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewNSBatchCollector(opts) }},
-	{"recycle", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewRecycleCollector(opts) }},
-	{"who", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewWhoCollector(opts) }},
-	{"quotas", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewQuotasCollector(opts) }},
-	{"fsck", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewFsckCollector(opts) }},
-	{"fusex", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewFusexCollector(opts) }},
-	{"inspector_layout", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"ns_batch", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewNSBatchCollector(opts) }},
+	{"recycle", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewRecycleCollector(opts) }},
+	{"who", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewWhoCollector(opts) }},
+	{"quotas", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewQuotasCollector(opts) }},
+	{"fsck", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewFsckCollector(opts) }},
+	{"fusex", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewFusexCollector(opts) }},
+	{"inspector_layout", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorLayoutCollector(opts)
 	}},
-	{"inspector_accesstime_volume", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_accesstime_volume", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorAccessTimeVolumeCollector(opts)
 	}},
-	{"inspector_accesstime_files", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_accesstime_files", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorAccessTimeFilesCollector(opts)
 	}},
-	{"inspector_birthtime_volume", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_birthtime_volume", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorBirthTimeVolumeCollector(opts)
 	}},
-	{"inspector_birthtime_files", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_birthtime_files", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorBirthTimeFilesCollector(opts)
 	}},
-	{"inspector_groupcost_disk", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_groupcost_disk", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorGroupCostDiskCollector(opts)
 	}},
-	{"inspector_groupcost_disktbyears", func(opts *collector.CollectorOpts) prometheus.Collector {
+	{"inspector_groupcost_disktbyears", func(opts *collector.CollectorOpts) collector.Collector {
 		return collector.NewInspectorGroupCostDiskTBYearsCollector(opts)
 	}},
-	{"audit", func(opts *collector.CollectorOpts) prometheus.Collector { return collector.NewAuditCollector(opts) }},
-}
-
-// EOSExporter wraps a list of registered EOS collectors
-type EOSExporter struct {
-	mu         sync.RWMutex
-	collectors []prometheus.Collector
-}
-
-var _ prometheus.Collector = &EOSExporter{}
-
-func (c *EOSExporter) Describe(ch chan<- *prometheus.Desc) {
-	for _, cc := range c.collectors {
-		cc.Describe(ch)
-	}
-}
-
-func (c *EOSExporter) Collect(ch chan<- prometheus.Metric) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	for _, cc := range c.collectors {
-		cc.Collect(ch)
-	}
+	{"audit", func(opts *collector.CollectorOpts) collector.Collector { return collector.NewAuditCollector(opts) }},
 }
 
 type Options struct {
@@ -134,8 +114,28 @@ type Options struct {
 	Version            bool
 	Help               bool
 	Timeout            int
+	EosBinary          string
+	MGMURL             string
+	RecordDir          string
+	ReplayDir          string
+	BackgroundPolling  bool
+	PollInterval       int
+	PollIntervals      string
+	MaxConcurrency     int
+	CoalesceWindow     time.Duration
+	MaxCommands        int
+	CircuitThreshold   int
+	CircuitBackoff     time.Duration
+	ConfigFile         string
+	StalePolicy        string
+	StalePolicies      string
 	AuditLogPath       string
 	AuditPollInterval  int
+	WebConfigFile      string
+	EnableDebugEOS     bool
+	DebugEOSRedact     string
+	Once               bool
+	Output             string
 }
 
 var cmdOptions *Options = &Options{}
@@ -147,17 +147,31 @@ func init() {
 	flag.StringVar(&cmdOptions.MetricsPath, "telemetry-path", "/metrics", "Path under which to expose metrics.")
 	flag.IntVar(&cmdOptions.Timeout, "timeout", 30, "Number of seconds to timeout when querying EOS.")
 	flag.StringVar(&cmdOptions.EOSInstance, "eos-instance", "", "EOS instance name.")
+	flag.StringVar(&cmdOptions.MGMURL, "mgm-url", "", "URL of the MGM to query, e.g. root://eos-example.cern.ch. Defaults to $EOS_MGM_URL, then to EOS_MGM_ALIAS in "+eosEnvFile+".")
+	flag.StringVar(&cmdOptions.EosBinary, "eos-binary", "/usr/bin/eos", "Path to the eos client binary used to query EOS.")
+	flag.StringVar(&cmdOptions.RecordDir, "record-dir", "", "Save the raw output of every eos command into a timestamped fixture directory under this path.")
+	flag.StringVar(&cmdOptions.ReplayDir, "replay-dir", "", "Serve the eos command outputs recorded in this fixture directory instead of calling the eos CLI.")
+	flag.StringVar(&cmdOptions.ConfigFile, "config-file", "", "YAML file with per-collector settings overriding the flags. Reloaded on SIGHUP.")
 	flag.StringVar(&cmdOptions.Collectors, "collectors", "all", "Comma-separated list of standard collectors to enable (e.g. 'space,node'). Default is 'all'.")
+	flag.BoolVar(&cmdOptions.BackgroundPolling, "background-polling", false, "Refresh the collectors in the background on their own interval and serve the latest results on scrape.")
+	flag.IntVar(&cmdOptions.PollInterval, "poll-interval", 30, "Default interval in seconds between background refreshes of a collector.")
+	flag.StringVar(&cmdOptions.PollIntervals, "poll-intervals", "", "Comma-separated per-collector background refresh intervals overriding the defaults (e.g. 'fs=30s,quotas=10m').")
+	flag.IntVar(&cmdOptions.MaxConcurrency, "max-concurrency", 4, "Maximum number of collectors running concurrently. Each collector run is bounded by --timeout.")
+	flag.DurationVar(&cmdOptions.CoalesceWindow, "coalesce-window", 0, "Scrapes arriving within this duration after a collection finished are served its results instead of running the collectors again. Concurrent scrapes always share one collection.")
+	flag.IntVar(&cmdOptions.MaxCommands, "max-eos-commands", 0, "Maximum number of eos commands running concurrently against an instance, 0 for no limit.")
+	flag.IntVar(&cmdOptions.CircuitThreshold, "circuit-threshold", 0, "Number of consecutive failures or timeouts of an eos command after which it is not run for --circuit-backoff, 0 to always run it.")
+	flag.DurationVar(&cmdOptions.CircuitBackoff, "circuit-backoff", time.Minute, "Duration an eos command failing repeatedly is not run.")
+	flag.StringVar(&cmdOptions.StalePolicy, "stale-policy", "drop", "What to serve when a collector fails: 'drop' its series, the last successful metrics for up to a duration (e.g. '10m'), or 'forever'.")
+	flag.StringVar(&cmdOptions.StalePolicies, "stale-policies", "", "Comma-separated per-collector stale policies overriding --stale-policy (e.g. 'fs=10m,quotas=forever').")
 	flag.StringVar(&cmdOptions.AuditLogPath, "audit-log-path", "/var/log/eos/mgm/audit/audit.zstd", "Path to the EOS audit log symlink. Default is standard EOS path.")
 	flag.IntVar(&cmdOptions.AuditPollInterval, "audit-poll-interval", 30, "Interval in seconds to check for new audit log files.")
+	flag.StringVar(&cmdOptions.WebConfigFile, "web-config-file", "", "Web configuration file of the Prometheus exporter-toolkit format enabling TLS, basic auth or client certificates on every listener.")
+	flag.BoolVar(&cmdOptions.EnableDebugEOS, "enable-debug-eos", false, "Serve the raw outputs of the last run of each eos command on /debug/eos of --listen-address.")
+	flag.StringVar(&cmdOptions.DebugEOSRedact, "debug-eos-redact", defaultDebugEOSRedact, "Comma-separated fields whose values are redacted on /debug/eos, e.g. uids and client hostnames.")
+	flag.BoolVar(&cmdOptions.Once, "once", false, "Run the enabled collectors a single time, write their metrics to --output and exit.")
+	flag.StringVar(&cmdOptions.Output, "output", "", "File the metrics are written to with --once, e.g. for the node_exporter textfile collector.")
 	flag.BoolVar(&cmdOptions.Help, "help", false, "Show the help and exit.")
 	flag.BoolVar(&cmdOptions.Version, "version", false, "Show the version and exit.")
-	flag.Parse()
-
-	if err := validate(); err != nil {
-		fmt.Printf("Error: %s\n", err.Error())
-		printUsage()
-	}
 }
 
 func validate() error {
@@ -169,6 +183,49 @@ func validate() error {
 		return errors.New("specify an EOS instance using the --eos-instance flag")
 	}
 
+	if cmdOptions.RecordDir != "" && cmdOptions.ReplayDir != "" {
+		return errors.New("--record-dir and --replay-dir are mutually exclusive")
+	}
+
+	// The flags alone define the standard and fast endpoints, which must not clash
+	if err := (&Config{}).validate(); err != nil {
+		return err
+	}
+
+	if cmdOptions.MaxConcurrency <= 0 {
+		return errors.New("--max-concurrency must be positive")
+	}
+
+	if cmdOptions.PollInterval <= 0 {
+		return errors.New("--poll-interval must be positive")
+	}
+
+	if _, err := parseDurations(cmdOptions.PollIntervals); err != nil {
+		return fmt.Errorf("invalid --poll-intervals: %w", err)
+	}
+
+	if _, err := parseStalePolicy(cmdOptions.StalePolicy); err != nil {
+		return fmt.Errorf("invalid --stale-policy: %w", err)
+	}
+
+	if _, err := parseStalePolicies(cmdOptions.StalePolicies); err != nil {
+		return fmt.Errorf("invalid --stale-policies: %w", err)
+	}
+
+	if cmdOptions.Once != (cmdOptions.Output != "") {
+		return errors.New("--once and --output go together")
+	}
+
+	if cmdOptions.Once && cmdOptions.BackgroundPolling {
+		return errors.New("--once and --background-polling are mutually exclusive")
+	}
+
+	if cmdOptions.WebConfigFile != "" {
+		if err := web.Validate(cmdOptions.WebConfigFile); err != nil {
+			return fmt.Errorf("invalid --web-config-file: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -188,23 +245,184 @@ func printVersion() {
 	os.Exit(0)
 }
 
-// createServer builds an HTTP server for a specific registry to isolate the metrics paths cleanly
-func createServer(address, path string, registry *prometheus.Registry) *http.Server {
-	mux := http.NewServeMux()
-	mux.Handle(path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(`<html>
-          <head><title>EOS Exporter</title></head>
-          <body>
-          <h1>EOS Exporter</h1>
-          <p><a href="` + path + `">Metrics</a></p>
-          </body>
-          </html>`))
-	})
-	return &http.Server{Addr: address, Handler: mux}
+// eosEnvFile is the EOS configuration of the MGM hosts
+const eosEnvFile = "/etc/sysconfig/eos_env"
+
+// mgmURL returns the URL of the MGM to query, from the --mgm-url flag, the
+// EOS_MGM_URL environment variable or the EOS_MGM_ALIAS of envFile, in that order
+func mgmURL(envFile string) (string, error) {
+	if cmdOptions.MGMURL != "" {
+		return cmdOptions.MGMURL, nil
+	}
+	if url := os.Getenv("EOS_MGM_URL"); url != "" {
+		return url, nil
+	}
+
+	file, err := os.Open(envFile)
+	if err != nil {
+		return "", fmt.Errorf("no --mgm-url nor EOS_MGM_URL, and %w", err)
+	}
+	defer file.Close()
+
+	var alias string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "EOS_MGM_ALIAS="); ok {
+			alias = strings.Trim(strings.TrimSpace(value), "\"")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading %s: %w", envFile, err)
+	}
+	if alias == "" {
+		return "", fmt.Errorf("no --mgm-url nor EOS_MGM_URL, and no EOS_MGM_ALIAS in %s", envFile)
+	}
+	return "root://" + alias, nil
+}
+
+// parseDurations parses a comma-separated list of name=duration pairs
+func parseDurations(list string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=duration, got %q", item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("duration for %s must be positive", name)
+		}
+		durations[strings.TrimSpace(name)] = d
+	}
+	return durations, nil
+}
+
+// parseStalePolicy parses "drop", "forever" or the duration during which the
+// last successful metrics of a failing collector are served
+func parseStalePolicy(value string) (stalePolicy, error) {
+	switch value = strings.TrimSpace(value); value {
+	case "drop":
+		return staleDrop, nil
+	case "forever":
+		return staleForever, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return staleDrop, fmt.Errorf("expected drop, forever or a duration, got %q", value)
+	}
+	if d <= 0 {
+		return staleDrop, fmt.Errorf("stale duration must be positive, got %q", value)
+	}
+	return stalePolicy(d), nil
+}
+
+// parseStalePolicies parses a comma-separated list of name=policy pairs
+func parseStalePolicies(list string) (map[string]stalePolicy, error) {
+	policies := make(map[string]stalePolicy)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=policy, got %q", item)
+		}
+		policy, err := parseStalePolicy(value)
+		if err != nil {
+			return nil, err
+		}
+		policies[strings.TrimSpace(name)] = policy
+	}
+	return policies, nil
+}
+
+// pollInterval returns the background refresh interval of a collector. Expensive
+// collectors refresh less often than the cheap and fast changing ones.
+func pollInterval(name string, overrides map[string]time.Duration) time.Duration {
+	if d, ok := overrides[name]; ok {
+		return d
+	}
+	switch {
+	case strings.HasPrefix(name, "inspector_"):
+		return time.Hour
+	case name == "quotas":
+		return 5 * time.Minute
+	case name == "fs" || name == "node":
+		return 15 * time.Second
+	}
+	return time.Duration(cmdOptions.PollInterval) * time.Second
+}
+
+// newExecutor returns the executor used by all collectors to run eos commands,
+// recording or replaying the raw outputs when requested
+func newExecutor() (eosclient.Executor, error) {
+	if cmdOptions.ReplayDir != "" {
+		if _, err := os.Stat(cmdOptions.ReplayDir); err != nil {
+			return nil, fmt.Errorf("replay directory: %w", err)
+		}
+		log.Println("Replaying eos command outputs from", cmdOptions.ReplayDir)
+		return &eosclient.ReplayExecutor{Dir: cmdOptions.ReplayDir}, nil
+	}
+
+	executor := eosclient.Executor(&eosclient.CommandExecutor{Binary: cmdOptions.EosBinary})
+	if cmdOptions.RecordDir != "" {
+		recorder, err := eosclient.NewRecordingExecutor(executor, cmdOptions.RecordDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Recording eos command outputs into", recorder.Dir())
+		executor = recorder
+	}
+	return executor, nil
+}
+
+// guards hands out the executors protecting each MGM, so that -max-eos-commands and the
+// circuit breakers apply to all the commands run against an instance, e.g. both by the
+// endpoints and by the probes targeting the local instance
+type guards struct {
+	executor eosclient.Executor
+
+	mu    sync.Mutex
+	byURL map[string]*eosclient.GuardedExecutor
+}
+
+func newGuards(executor eosclient.Executor) *guards {
+	return &guards{executor: executor, byURL: make(map[string]*eosclient.GuardedExecutor)}
+}
+
+// get returns the executor protecting the MGM of url from the commands run through it
+func (g *guards) get(url string) *eosclient.GuardedExecutor {
+	url = strings.TrimRight(url, "/")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	guard, ok := g.byURL[url]
+	if !ok {
+		guard = eosclient.NewGuardedExecutor(g.executor, eosclient.GuardOptions{
+			MaxConcurrent: cmdOptions.MaxCommands,
+			Threshold:     cmdOptions.CircuitThreshold,
+			Backoff:       cmdOptions.CircuitBackoff,
+		})
+		g.byURL[url] = guard
+	}
+	return guard
 }
 
 func main() {
+	flag.Parse()
+
+	if err := validate(); err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		printUsage()
+	}
+
 	if cmdOptions.Help {
 		printUsage()
 	}
@@ -213,117 +431,205 @@ func main() {
 		printVersion()
 	}
 
+	executor, err := newExecutor()
+	if err != nil {
+		log.Fatalf("Failed to set up eos command execution: %v", err)
+	}
+
+	// Replayed outputs do not need an MGM, the URL is then only part of the fixture names
+	mgm, err := mgmURL(eosEnvFile)
+	if err != nil && cmdOptions.ReplayDir == "" {
+		log.Fatalf("Failed to determine the MGM to query: %v", err)
+	}
+	if !strings.Contains(mgm, "://") && mgm != "" {
+		log.Fatalf("Invalid MGM URL %q, expected e.g. root://eos-example.cern.ch", mgm)
+	}
+
+	var trace *eosclient.CommandTrace
+	if cmdOptions.EnableDebugEOS {
+		trace = eosclient.NewCommandTrace()
+	}
+
+	// One long-lived client per instance, shared by all the collectors
+	newClient := func(url string, executor eosclient.Executor, metrics *eosclient.CommandMetrics) (*eosclient.Client, error) {
+		return eosclient.New(&eosclient.Options{
+			URL:       url,
+			Timeout:   cmdOptions.Timeout,
+			EosBinary: cmdOptions.EosBinary,
+			Executor:  executor,
+			Trace:     trace,
+			Metrics:   metrics,
+		})
+	}
+	guards := newGuards(executor)
+	guard := guards.get(mgm)
+	client, err := newClient(mgm, guard, nil)
+	if err != nil {
+		log.Fatalf("Failed to create the eos client: %v", err)
+	}
+
 	collectorOpts := &collector.CollectorOpts{
 		Cluster:           cmdOptions.EOSInstance,
-		Timeout:           cmdOptions.Timeout,
+		Client:            client,
 		AuditLogPath:      cmdOptions.AuditLogPath,
 		AuditPollInterval: cmdOptions.AuditPollInterval,
 	}
 
-	log.Println("Starting eos exporter for instance", cmdOptions.EOSInstance)
+	log.Printf("Starting eos exporter for instance %s querying %s", cmdOptions.EOSInstance, mgm)
 
-	fastCollectorsSet := map[string]bool{
-		"traffic_shaping_io":     true,
-		"traffic_shaping_policy": true,
-		// Add future fast metrics here
+	exporterOpts := exporterOpts{
+		Background: cmdOptions.BackgroundPolling,
+		Workers:    cmdOptions.MaxConcurrency,
+		Coalesce:   cmdOptions.CoalesceWindow,
 	}
 
-	// Fast metrics will not be exposed in the standard endpoint to avoid duplication!
-	isFastCollector := func(name string) bool {
-		return fastCollectorsSet[name]
+	loadConfigFile := func() (*Config, error) {
+		if cmdOptions.ConfigFile == "" {
+			return &Config{}, nil
+		}
+		return loadConfig(cmdOptions.ConfigFile)
+	}
+	cfg, err := loadConfigFile()
+	if err != nil {
+		log.Fatalf("Failed to load the configuration: %v", err)
 	}
 
-	requestedMap := make(map[string]bool)
-	if cmdOptions.Collectors != "all" && cmdOptions.Collectors != "" {
-		for _, r := range strings.Split(cmdOptions.Collectors, ",") {
-			requestedMap[strings.TrimSpace(r)] = true
+	if cmdOptions.Once {
+		exporter := newEOSExporter(cmdOptions.EOSInstance, collector.NewSnapshot(guard, cmdOptions.EOSInstance), exporterOpts)
+		err := writeOnce(cmdOptions.Output, exporter, collectorSpecs(cfg, *collectorOpts))
+		exporter.Stop()
+		if err != nil {
+			log.Fatalf("Failed to write the metrics to %s: %v", cmdOptions.Output, err)
 		}
+		log.Println("Metrics written to", cmdOptions.Output)
+		return
 	}
 
-	var slowCollectors []prometheus.Collector
-	var fastCollectors []prometheus.Collector
-
-	// Distribute collectors based on type and flags
-	for _, c := range availableCollectors {
-		if isFastCollector(c.name) {
-			if cmdOptions.EnableFastExporter {
-				fastCollectors = append(fastCollectors, c.creator(collectorOpts))
-			}
-		} else {
-			// Slow collectors obey the --collectors flag
-			if cmdOptions.Collectors == "all" || cmdOptions.Collectors == "" || requestedMap[c.name] {
-				slowCollectors = append(slowCollectors, c.creator(collectorOpts))
-			}
+	// Each endpoint is scraped independently, so each one gets its own exporter and per-scrape snapshot
+	layout := cfg.endpoints()
+	var endpoints []*endpoint
+	for _, epCfg := range layout {
+		ep := &endpoint{
+			EndpointConfig: epCfg,
+			exporter:       newEOSExporter(cmdOptions.EOSInstance, collector.NewSnapshot(guard, cmdOptions.EOSInstance), exporterOpts),
+			registry:       prometheus.NewRegistry(),
 		}
+		if ep.Name == endpointStandard {
+			ep.registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+			ep.registry.MustRegister(collectors.NewGoCollector())
+			prometheus.WrapRegistererWith(prometheus.Labels{"cluster": cmdOptions.EOSInstance}, ep.registry).MustRegister(append(eosclient.Metrics(), guard)...)
+		}
+		defer ep.exporter.Stop()
+		endpoints = append(endpoints, ep)
 	}
 
-	var fastServer *http.Server
-	if cmdOptions.EnableFastExporter {
-		fastRegistry := prometheus.NewRegistry()
-		if len(fastCollectors) > 0 {
-			fastRegistry.MustRegister(&EOSExporter{collectors: fastCollectors})
+	// The readiness probe reaches the MGM even when the circuit of its command is open
+	// or the collectors took all the -max-eos-commands slots, the client timeout bounds it
+	ready := &readiness{binary: cmdOptions.EosBinary, client: client.WithExecutor(executor), replay: cmdOptions.ReplayDir != ""}
+
+	// Distribute collectors based on type, flags and configuration file
+	apply := func(cfg *Config) {
+		specs := collectorSpecs(cfg, *collectorOpts)
+		auditPath := ""
+		for _, ep := range endpoints {
+			ep.exporter.apply(specs[ep.Name])
+			for _, spec := range specs[ep.Name] {
+				if spec.name == "audit" {
+					auditPath = spec.opts.AuditLogPath
+				}
+			}
 		}
-		fastServer = createServer(cmdOptions.ListenAddressFast, cmdOptions.MetricsPath, fastRegistry)
+		ready.setAuditLogPath(auditPath)
 	}
+	apply(cfg)
 
-	stdRegistry := prometheus.NewRegistry()
+	probe := newProber(guards, newClient, *collectorOpts, cfg)
 
-	stdRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-	stdRegistry.MustRegister(collectors.NewGoCollector())
+	if cmdOptions.BackgroundPolling {
+		log.Println("Background polling enabled, scrapes serve the latest collected metrics")
+	}
+	if !cmdOptions.EnableFastExporter {
+		log.Println("Fast metrics exporter disabled")
+	}
 
-	if len(slowCollectors) > 0 {
-		stdRegistry.MustRegister(&EOSExporter{collectors: slowCollectors})
+	var addresses []string
+	byAddress := make(map[string][]*endpoint)
+	for _, ep := range endpoints {
+		if _, ok := byAddress[ep.ListenAddress]; !ok {
+			addresses = append(addresses, ep.ListenAddress)
+		}
+		byAddress[ep.ListenAddress] = append(byAddress[ep.ListenAddress], ep)
 	}
 
-	stdServer := createServer(cmdOptions.ListenAddress, cmdOptions.MetricsPath, stdRegistry)
+	status := &statusPage{endpoints: endpoints}
 
-	if cmdOptions.EnableFastExporter {
+	var servers []*http.Server
+	for _, address := range addresses {
+		// The probes and the debug outputs are served by the listener of the standard endpoint
+		handlers := map[string]http.Handler{
+			"/-/healthy":  http.HandlerFunc(healthy),
+			"/-/ready":    ready,
+			"/":           status.page(byAddress[address]),
+			"/api/status": status,
+		}
+		if address == cmdOptions.ListenAddress {
+			handlers["/probe"] = probe
+			if trace != nil {
+				handlers["/debug/eos"] = newDebugEOS(trace, cmdOptions.DebugEOSRedact)
+			}
+		}
+		server := createServer(address, byAddress[address], handlers)
+		servers = append(servers, server)
 		go func() {
-			log.Println("Fast metrics listening on", cmdOptions.ListenAddressFast)
-			if err := fastServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				log.Fatalf("Fast server failed: %v", err)
+			for _, ep := range byAddress[address] {
+				log.Printf("Serving %s metrics on %s%s", ep.Name, address, ep.Path)
+			}
+			// Without a web config file the exporter-toolkit serves plain HTTP
+			err := web.ListenAndServe(server, cmdOptions.WebConfigFile, kitlog.NewLogfmtLogger(log.Writer()))
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Server on %s failed: %v", address, err)
 			}
 		}()
-	} else {
-		log.Println("Fast metrics exporter disabled")
 	}
 
-	go func() {
-		log.Println("Standard metrics listening on", cmdOptions.ListenAddress)
-		if err := stdServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("Standard server failed: %v", err)
-		}
-	}()
-
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	<-quit
+	// SIGHUP rebuilds the collectors, the listeners keep serving
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for running := true; running; {
+		select {
+		case <-hup:
+			log.Println("SIGHUP received, reloading the configuration")
+			cfg, err := reloadConfig(loadConfigFile, layout)
+			if err != nil {
+				log.Printf("Failed to reload the configuration, keeping the current one: %v", err)
+				continue
+			}
+			apply(cfg)
+			probe.reload(cfg)
+		case <-quit:
+			running = false
+		}
+	}
 	log.Println("Interrupt signal received. Shutting down servers gracefully...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var wg sync.WaitGroup
-	wg.Add(1)
-
-	if fastServer != nil {
+	for _, server := range servers {
 		wg.Add(1)
-		go func() {
+		go func(server *http.Server) {
 			defer wg.Done()
-			if err := fastServer.Shutdown(ctx); err != nil {
-				log.Printf("Fast server shutdown error: %v", err)
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("Server on %s shutdown error: %v", server.Addr, err)
 			}
-		}()
+		}(server)
 	}
 
-	go func() {
-		defer wg.Done()
-		if err := stdServer.Shutdown(ctx); err != nil {
-			log.Printf("Standard server shutdown error: %v", err)
-		}
-	}()
-
 	wg.Wait()
 	log.Println("EOS Exporter successfully stopped.")
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMGMURL(t *testing.T) {
+	dir := t.TempDir()
+	envFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	withAlias := envFile("eos_env", "EOS_INSTANCE_NAME=eosexample\nEOS_MGM_ALIAS=\"eos-alias.cern.ch\"\n")
+	withoutAlias := envFile("eos_env_no_alias", "EOS_INSTANCE_NAME=eosexample\n")
+
+	for _, tc := range []struct {
+		name    string
+		flag    string
+		env     string
+		envFile string
+		want    string
+		err     string
+	}{
+		{name: "flag first", flag: "root://eos-flag.cern.ch", env: "root://eos-env.cern.ch", envFile: withAlias, want: "root://eos-flag.cern.ch"},
+		{name: "then the environment", env: "root://eos-env.cern.ch", envFile: withAlias, want: "root://eos-env.cern.ch"},
+		{name: "then the eos_env alias", envFile: withAlias, want: "root://eos-alias.cern.ch"},
+		{name: "no alias", envFile: withoutAlias, err: "no EOS_MGM_ALIAS in " + withoutAlias},
+		{name: "no eos_env", envFile: filepath.Join(dir, "missing"), err: "no --mgm-url nor EOS_MGM_URL, and open"},
+	} {
+		setOptions(t, func(o *Options) { o.MGMURL = tc.flag })
+		t.Setenv("EOS_MGM_URL", tc.env)
+
+		got, err := mgmURL(tc.envFile)
+		switch {
+		case tc.err != "":
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: expected an error containing %q, got %q, %v", tc.name, tc.err, got, err)
+			}
+		case err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case got != tc.want:
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package eosclient
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// decodeReport lists the differences between a line of monitoring format output
+// and the struct it was decoded into, which usually come from an EOS upgrade
+type decodeReport struct {
+	Unknown []string // keys of the line no field is tagged with
+	Missing []string // keys of the tagged fields absent from the line
+	Invalid []string // keys whose value could not be converted to the type of their field
+}
+
+// decodeField is a field of a struct tagged with `eos:"<key>"`
+type decodeField struct {
+	key   string
+	index int
+}
+
+// decodeFields caches the tagged fields of the decoded struct types
+var decodeFields sync.Map // reflect.Type -> []decodeField
+
+func fieldsOf(t reflect.Type) []decodeField {
+	if fields, ok := decodeFields.Load(t); ok {
+		return fields.([]decodeField)
+	}
+	var fields []decodeField
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("eos")
+		if key == "" || key == "-" {
+			continue
+		}
+		fields = append(fields, decodeField{key: key, index: i})
+	}
+	decodeFields.Store(t, fields)
+	return fields
+}
+
+// decodeMonitoring fills the fields of the struct pointed to by v tagged with
+// `eos:"<key>"` from the key=value pairs of a line of monitoring format output,
+// as returned by getMap. Fields may be strings, integers, floats or booleans, or
+// pointers to them. A missing or
+// empty value leaves the field to its zero value, nil for a pointer, so that an
+// unknown value can be told from a zero one. The fields whose value can not be
+// converted are left unset as well and reported in the error.
+func decodeMonitoring(kv map[string]string, v any) (decodeReport, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("decodeMonitoring: expected a pointer to a struct, got %T", v))
+	}
+	rv = rv.Elem()
+
+	var report decodeReport
+	var invalid []string // key="value"
+	known := make(map[string]bool)
+	for _, f := range fieldsOf(rv.Type()) {
+		known[f.key] = true
+		value, ok := kv[f.key]
+		if !ok {
+			report.Missing = append(report.Missing, f.key)
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		if err := setField(rv.Field(f.index), value); err != nil {
+			report.Invalid = append(report.Invalid, f.key)
+			invalid = append(invalid, fmt.Sprintf("%s=%q", f.key, value))
+		}
+	}
+	for key := range kv {
+		if !known[key] {
+			report.Unknown = append(report.Unknown, key)
+		}
+	}
+	sort.Strings(report.Unknown)
+
+	if len(invalid) > 0 {
+		return report, fmt.Errorf("invalid values for %s: %s", rv.Type().Name(), strings.Join(invalid, ", "))
+	}
+	return report, nil
+}
+
+// setField converts value to the type of the field
+func setField(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// parseBool parses the booleans of the eos output, e.g. 1, true or on
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// decode decodes a line of monitoring format output into v with decodeMonitoring
+// and counts its unknown, missing and invalid keys in the command metrics. The fields
+// of the invalid values are left unset, the other ones being decoded anyway.
+func (c *Client) decode(line string, v any) {
+	report, _ := decodeMonitoring(c.getMap(line), v)
+	c.opt.Metrics.observeDecode(reflect.TypeOf(v).Elem().Name(), report)
+}
+
+// decodeStat is decode for the key=value pairs of kv, which hold some of the keys of
+// v only: the other keys are not counted as missing.
+func (c *Client) decodeStat(kv map[string]string, v any) {
+	report, _ := decodeMonitoring(kv, v)
+	report.Missing = nil
+	c.opt.Metrics.observeDecode(reflect.TypeOf(v).Elem().Name(), report)
+}
@@ -0,0 +1,111 @@
+package eosclient
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type decodeTestInfo struct {
+	Name    string  `eos:"name"`
+	Bytes   uint64  `eos:"stat.statfs.freebytes"`
+	Load    float64 `eos:"stat.disk.load"`
+	Active  bool    `eos:"stat.active"`
+	Missing int64   `eos:"stat.drainfiles"`
+	Ignored string
+}
+
+func TestDecodeMonitoring(t *testing.T) {
+	kv := map[string]string{
+		"name":                  "default",
+		"stat.statfs.freebytes": "1099511627776",
+		"stat.disk.load":        "0.25",
+		"stat.active":           "1",
+		"stat.new":              "x",
+	}
+
+	var info decodeTestInfo
+	report, err := decodeMonitoring(kv, &info)
+	if err != nil {
+		t.Fatalf("decodeMonitoring returned error: %v", err)
+	}
+
+	want := decodeTestInfo{Name: "default", Bytes: 1 << 40, Load: 0.25, Active: true}
+	if info != want {
+		t.Fatalf("got %+v, want %+v", info, want)
+	}
+	if !reflect.DeepEqual(report.Unknown, []string{"stat.new"}) {
+		t.Fatalf("expected unknown key stat.new, got %v", report.Unknown)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"stat.drainfiles"}) {
+		t.Fatalf("expected missing key stat.drainfiles, got %v", report.Missing)
+	}
+}
+
+func TestDecodeMonitoringInvalidValue(t *testing.T) {
+	var info decodeTestInfo
+	_, err := decodeMonitoring(map[string]string{"name": "default", "stat.statfs.freebytes": "lots"}, &info)
+	if err == nil || !strings.Contains(err.Error(), "stat.statfs.freebytes") {
+		t.Fatalf("expected an error naming stat.statfs.freebytes, got %v", err)
+	}
+	if info.Name != "default" || info.Bytes != 0 {
+		t.Fatalf("expected the valid fields to be decoded, got %+v", info)
+	}
+}
+
+func TestDecodeMonitoringPointers(t *testing.T) {
+	var info struct {
+		Free    *int64   `eos:"stat.statfs.freebytes"`
+		Load    *float64 `eos:"stat.disk.load"`
+		Missing *int64   `eos:"stat.drainfiles"`
+	}
+	kv := map[string]string{"stat.statfs.freebytes": "0", "stat.disk.load": "high"}
+	_, err := decodeMonitoring(kv, &info)
+	if err == nil || !strings.Contains(err.Error(), "stat.disk.load") {
+		t.Fatalf("expected an error naming stat.disk.load, got %v", err)
+	}
+	if info.Free == nil || *info.Free != 0 {
+		t.Fatalf("expected a known zero free bytes, got %v", info.Free)
+	}
+	if info.Load != nil || info.Missing != nil {
+		t.Fatalf("expected the invalid and missing values to be nil, got %+v", info)
+	}
+}
+
+func TestDecodeCountsKeys(t *testing.T) {
+	metrics := NewCommandMetrics()
+	client, err := New(&Options{Executor: &fixtureExecutor{}, Metrics: metrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var info decodeTestInfo
+	client.decode("name=default stat.statfs.freebytes=lots stat.disk.load=0.25 stat.active=1 stat.new=x", &info)
+	if info.Name != "default" || info.Bytes != 0 || info.Load != 0.25 {
+		t.Fatalf("unexpected decoded line %+v", info)
+	}
+	// A namespace stat per line, the other stats are not missing
+	nsinfos, _, _, err := client.parseNSsInfo("uid=all gid=all ns.total.files=12\nuid=all gid=all ns.new.stat=3\n", "", "", context.Background())
+	if err != nil || len(nsinfos) != 2 || nsinfos[0].Total_files == nil || *nsinfos[0].Total_files != 12 {
+		t.Fatalf("unexpected namespace stats %v, %v", nsinfos, err)
+	}
+
+	for _, tc := range []struct {
+		typ, key, problem string
+		count             float64
+	}{
+		{"decodeTestInfo", "stat.new", "unknown", 1},
+		{"decodeTestInfo", "stat.drainfiles", "missing", 1},
+		{"decodeTestInfo", "stat.statfs.freebytes", "invalid", 1},
+		{"NSInfo", "ns.new.stat", "unknown", 1},
+		{"NSInfo", "ns.uptime", "missing", 0},
+		{"NSInfo", "uid", "unknown", 0},
+	} {
+		if got := testutil.ToFloat64(metrics.keys.WithLabelValues(tc.typ, tc.key, tc.problem)); got != tc.count {
+			t.Errorf("%s %s %s: expected %v, got %v", tc.typ, tc.key, tc.problem, tc.count, got)
+		}
+	}
+}
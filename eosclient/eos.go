@@ -4,7 +4,6 @@ package eosclient
 // This code can be vastly improved.
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -16,7 +15,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 	"unicode"
 
@@ -32,7 +30,8 @@ type Options struct {
 	// Location of the xrdcopy binary. Default is /usr/bin/xrdcopy.
 	XrdcopyBinary string
 
-	// URL of the EOS MGM. Default is root://eos-test.org
+	// URL of the EOS MGM, e.g. root://eos-example.org, passed to every eos
+	// command. Defaults to none, letting the eos CLI use EOS_MGM_URL.
 	URL string
 
 	// Location on the local fs where to store reads. Defaults to os.TempDir()
@@ -46,9 +45,19 @@ type Options struct {
 
 	// Timeout number of seconds before timing out requests to EOS
 	Timeout int
+
+	// Executor used to run the eos commands. Defaults to a CommandExecutor
+	// spawning EosBinary.
+	Executor Executor
+
+	// Trace keeps the raw outputs of the last commands when set. Defaults to none.
+	Trace *CommandTrace
+
+	// Metrics of the commands run. Defaults to the ones returned by Metrics.
+	Metrics *CommandMetrics
 }
 
-func (opt *Options) init() {
+func (opt *Options) init() error {
 	if opt.EosBinary == "" {
 		opt.EosBinary = "/usr/bin/eos"
 	}
@@ -57,22 +66,30 @@ func (opt *Options) init() {
 		opt.XrdcopyBinary = "/usr/bin/xrdcopy"
 	}
 
-	if opt.URL == "" {
-		opt.URL = "root://eos-example.org"
-	}
-
 	if opt.CacheDirectory == "" {
 		opt.CacheDirectory = os.TempDir()
 	}
 
 	if opt.Logger == nil {
-		l, _ := zap.NewProduction()
+		l, err := zap.NewProduction()
+		if err != nil {
+			return fmt.Errorf("creating the logger: %w", err)
+		}
 		opt.Logger = l
 	}
 
 	if opt.Timeout == 0 {
 		opt.Timeout = DEFAULT_TIMEOUT
 	}
+
+	if opt.Executor == nil {
+		opt.Executor = &CommandExecutor{Binary: opt.EosBinary}
+	}
+
+	if opt.Metrics == nil {
+		opt.Metrics = defaultMetrics
+	}
+	return nil
 }
 
 // Client performs actions against a EOS management node (MGM).
@@ -82,151 +99,152 @@ type Client struct {
 }
 
 type NodeInfo struct {
+	HostPort              string `eos:"hostport"`
 	Host                  string
 	Port                  string
-	Status                string
-	CfgStatus             string
-	Nofs                  string
-	HeartBeatDelta        string
-	SumStatStatfsFree     string
-	SumStatStatfsUsed     string
-	SumStatStatfsTotal    string
-	SumStatStatFilesFree  string
-	SumStatStatFilesUsed  string
-	SumStatStatFilesTotal string
-	SumStatRopen          string
-	SumStatWopen          string
-	CfgStatSysThreads     string
-	CfgStatSysVsize       string
-	CfgStatSysRss         string
-	CfgStatSysSockets     string
-	SumStatNetInratemib   string
-	SumStatNetOutratemib  string
-	EOSVersion            string
-	XRootDVersion         string
-	Kernel                string
-	Geotag                string
+	Status                ActiveStatus `eos:"status"`
+	CfgStatus             bool         `eos:"cfg.status"`
+	Nofs                  *float64     `eos:"nofs"`
+	HeartBeatDelta        *float64     `eos:"heartbeatdelta"`
+	SumStatStatfsFree     *float64     `eos:"sum.stat.statfs.freebytes"`
+	SumStatStatfsUsed     *float64     `eos:"sum.stat.statfs.usedbytes"`
+	SumStatStatfsTotal    *float64     `eos:"sum.stat.statfs.capacity"`
+	SumStatStatFilesFree  *float64     `eos:"sum.stat.statfs.ffree"`
+	SumStatStatFilesUsed  *float64     `eos:"sum.stat.usedfiles"`
+	SumStatStatFilesTotal *float64     `eos:"sum.stat.statfs.files"`
+	SumStatRopen          *float64     `eos:"sum.stat.ropen"`
+	SumStatWopen          *float64     `eos:"sum.stat.wopen"`
+	CfgStatSysThreads     *float64     `eos:"cfg.stat.sys.threads"`
+	CfgStatSysVsize       *float64     `eos:"cfg.stat.sys.vsize"`
+	CfgStatSysRss         *float64     `eos:"cfg.stat.sys.rss"`
+	CfgStatSysSockets     *float64     `eos:"cfg.stat.sys.sockets"`
+	SumStatNetInratemib   *float64     `eos:"sum.stat.net.inratemib"`
+	SumStatNetOutratemib  *float64     `eos:"sum.stat.net.outratemib"`
+	EOSVersion            string       `eos:"cfg.stat.sys.eos.version"`
+	XRootDVersion         string       `eos:"cfg.stat.sys.xrootd.version"`
+	Kernel                string       `eos:"cfg.stat.sys.kernel"`
+	Geotag                string       `eos:"cfg.stat.geotag"`
 }
 
 type GroupInfo struct {
-	Name                   string
-	CfgStatus              string
-	Nofs                   string
-	AvgStatDiskLoad        string
-	SigStatDiskLoad        string
-	SumStatDiskReadratemb  string
-	SumStatDiskWriteratemb string
-	SumStatNetEthratemib   string
-	SumStatNetInratemib    string
-	SumStatNetOutratemib   string
-	SumStatRopen           string
-	SumStatWopen           string
-	SumStatStatfsUsedbytes string
-	SumStatStatfsFreebytes string
-	SumStatStatfsCapacity  string
-	SumStatUsedfiles       string
-	SumStatStatfsFfree     string
-	SumStatStatfsFiles     string
-	DevStatStatfsFilled    string
-	AvgStatStatfsFilled    string
-	SigStatStatfsFilled    string
-	CfgStatBalancing       string
-	SumStatBalancerRunning string
-	SumStatDrainerRunning  string
+	Name                   string          `eos:"name"`
+	CfgStatus              bool            `eos:"cfg.status"`
+	Nofs                   *float64        `eos:"nofs"`
+	AvgStatDiskLoad        *float64        `eos:"avg.stat.disk.load"`
+	SigStatDiskLoad        *float64        `eos:"sig.stat.disk.load"`
+	SumStatDiskReadratemb  *float64        `eos:"sum.stat.disk.readratemb"`
+	SumStatDiskWriteratemb *float64        `eos:"sum.stat.disk.writeratemb"`
+	SumStatNetEthratemib   *float64        `eos:"sum.stat.net.ethratemib"`
+	SumStatNetInratemib    *float64        `eos:"sum.stat.net.inratemib"`
+	SumStatNetOutratemib   *float64        `eos:"sum.stat.net.outratemib"`
+	SumStatRopen           *float64        `eos:"sum.stat.ropen"`
+	SumStatWopen           *float64        `eos:"sum.stat.wopen"`
+	SumStatStatfsUsedbytes *float64        `eos:"sum.stat.statfs.usedbytes"`
+	SumStatStatfsFreebytes *float64        `eos:"sum.stat.statfs.freebytes"`
+	SumStatStatfsCapacity  *float64        `eos:"sum.stat.statfs.capacity"`
+	SumStatUsedfiles       *float64        `eos:"sum.stat.usedfiles"`
+	SumStatStatfsFfree     *float64        `eos:"sum.stat.statfs.ffree"`
+	SumStatStatfsFiles     *float64        `eos:"sum.stat.statfs.files"`
+	DevStatStatfsFilled    *float64        `eos:"dev.stat.statfs.filled"`
+	AvgStatStatfsFilled    *float64        `eos:"avg.stat.statfs.filled"`
+	SigStatStatfsFilled    *float64        `eos:"sig.stat.statfs.filled"`
+	CfgStatBalancing       BalancingStatus `eos:"cfg.stat.balancing"`
+	SumStatBalancerRunning *float64        `eos:"sum.stat.balancer.running"`
+	SumStatDrainerRunning  *float64        `eos:"sum.stat.drainer.running"`
 }
 
 type FSInfo struct {
-	Host                       string
-	Port                       string
-	Id                         string
-	Uuid                       string
-	Path                       string
-	Schedgroup                 string
-	StatBoot                   string
-	Configstatus               string
-	Headroom                   string
-	StatErrc                   string
-	StatErrmsg                 string
-	StatDiskLoad               string
-	StatDiskReadratemb         string
-	StatDiskWriteratemb        string
-	StatNetEthratemib          string
-	StatNetInratemib           string
-	StatNetOutratemib          string
-	StatRopen                  string
-	StatWopen                  string
-	StatStatfsFreebytes        string
-	StatStatfsUsedbytes        string
-	StatStatfsCapacity         string
-	StatUsedfiles              string
-	StatStatfsFfree            string
-	StatStatfsFused            string
-	StatStatfsFiles            string
-	Drainstatus                string
-	StatDrainprogress          string
-	StatDrainfiles             string
-	StatDrainbytesleft         string
-	StatDrainretry             string
-	StatDrainFailed            string
-	Graceperiod                string
-	StatTimeleft               string
-	StatActive                 string
-	StatBalancerRunning        string
-	StatDrainerRunning         string
-	StatDiskIops               string
-	StatDiskBw                 string
-	StatGeotag                 string
-	StatHealth                 string
-	StatHealthRedundancyFactor string
-	StatHealthDrivesFailed     string
-	StatHealthDrivesTotal      string
-	StatHealthIndicator        string
+	Host                       string       `eos:"host"`
+	Port                       string       `eos:"port"`
+	Id                         string       `eos:"id"`
+	Uuid                       string       `eos:"uuid"`
+	Path                       string       `eos:"path"`
+	Schedgroup                 string       `eos:"schedgroup"`
+	StatBoot                   BootStatus   `eos:"stat.boot"`
+	Configstatus               ConfigStatus `eos:"configstatus"`
+	Headroom                   *int64       `eos:"headroom"`
+	StatErrc                   *int64       `eos:"stat.errc"`
+	StatErrmsg                 string       `eos:"stat.errmsg"`
+	StatDiskLoad               *float64     `eos:"stat.disk.load"`
+	StatDiskReadratemb         *float64     `eos:"stat.disk.readratemb"`
+	StatDiskWriteratemb        *float64     `eos:"stat.disk.writeratemb"`
+	StatNetEthratemib          *float64     `eos:"stat.net.ethratemib"`
+	StatNetInratemib           *float64     `eos:"stat.net.inratemib"`
+	StatNetOutratemib          *float64     `eos:"stat.net.outratemib"`
+	StatRopen                  *float64     `eos:"stat.ropen"`
+	StatWopen                  *float64     `eos:"stat.wopen"`
+	StatStatfsFreebytes        *float64     `eos:"stat.statfs.freebytes"`
+	StatStatfsUsedbytes        *float64     `eos:"stat.statfs.usedbytes"`
+	StatStatfsCapacity         *float64     `eos:"stat.statfs.capacity"`
+	StatUsedfiles              *int64       `eos:"stat.usedfiles"`
+	StatStatfsFfree            *float64     `eos:"stat.statfs.ffree"`
+	StatStatfsFused            *float64     `eos:"stat.statfs.fused"`
+	StatStatfsFiles            *float64     `eos:"stat.statfs.files"`
+	Drainstatus                DrainStatus  `eos:"drainstatus"`
+	StatDrainprogress          *float64     `eos:"stat.drainprogress"`
+	StatDrainfiles             *int64       `eos:"stat.drainfiles"`
+	StatDrainbytesleft         *int64       `eos:"stat.drainbytesleft"`
+	StatDrainretry             *float64     `eos:"stat.drainretry"`
+	StatDrainFailed            *float64     `eos:"stat.drain.failed"`
+	Graceperiod                *int64       `eos:"graceperiod"`
+	StatTimeleft               *int64       `eos:"stat.timeleft"`
+	StatActive                 ActiveStatus `eos:"stat.active"`
+	StatBalancerRunning        *float64     `eos:"stat.balancer.running"`
+	StatDrainerRunning         *float64     `eos:"stat.drainer.running"`
+	StatDiskIops               *float64     `eos:"stat.disk.iops"`
+	StatDiskBw                 *float64     `eos:"stat.disk.bw"`
+	StatGeotag                 string       `eos:"stat.geotag"`
+	StatHealth                 string       `eos:"stat.health"`
+	StatHealthRedundancyFactor string       `eos:"stat.health.redundancy_factor"`
+	StatHealthDrivesFailed     *int64       `eos:"stat.health.drives_failed"`
+	StatHealthDrivesTotal      *int64       `eos:"stat.health.drives_total"`
+	StatHealthIndicator        string       `eos:"stat.health.indicator"`
 }
 
 type NSInfo struct {
-	Boot_file_time                             string
-	Boot_status                                string
-	Boot_time                                  string
-	Cache_container_maxsize                    string
-	Cache_container_occupancy                  string
-	Cache_files_maxsize                        string
-	Cache_files_occupancy                      string
-	Fds_all                                    string
-	Fusex_activeclients                        string
-	Fusex_caps                                 string
-	Fusex_clients                              string
-	Fusex_lockedclients                        string
-	Hanging_since                              string
-	Latency_dirs                               string
-	Latency_files                              string
-	Latency_pending_updates                    string
-	Latencypeak_eosviewmutex_1min              string
-	Latencypeak_eosviewmutex_2min              string
-	Latencypeak_eosviewmutex_5min              string
-	Latencypeak_eosviewmutex_last              string
-	Qclient_rtt_ms_min                         string
-	Qclient_rtt_ms_avg                         string
-	Qclient_rtt_ms_max                         string
-	Qclient_rtt_ms_peak_1min                   string
-	Qclient_rtt_ms_peak_2min                   string
-	Qclient_rtt_ms_peak_5min                   string
-	Memory_growth                              string
-	Memory_resident                            string
-	Memory_share                               string
-	Memory_virtual                             string
-	Stat_threads                               string
-	Total_directories                          string
-	Total_directories_changelog_avg_entry_size string
-	Total_directories_changelog_size           string
-	Total_files                                string
-	Total_files_changelog_avg_entry_size       string
-	Total_files_changelog_size                 string
-	Uptime                                     string
-	Cache_files_requests                       string
-	Cache_files_hits                           string
-	Cache_containers_requests                  string
-	Cache_containers_hits                      string
-	Traffic_shaping_enabled                    string
+	Boot_file_time                             *float64 `eos:"ns.boot.file.time"`
+	Boot_status                                string   `eos:"ns.boot.status"`
+	Boot_time                                  *float64 `eos:"ns.boot.time"`
+	Cache_container_maxsize                    *float64 `eos:"ns.cache.containers.maxsize"`
+	Cache_container_occupancy                  *float64 `eos:"ns.cache.containers.occupancy"`
+	Cache_files_maxsize                        *float64 `eos:"ns.cache.files.maxsize"`
+	Cache_files_occupancy                      *float64 `eos:"ns.cache.files.occupancy"`
+	Fds_all                                    *float64 `eos:"ns.fds.all"`
+	Fusex_activeclients                        *float64 `eos:"ns.fusex.activeclients"`
+	Fusex_caps                                 *float64 `eos:"ns.fusex.caps"`
+	Fusex_clients                              *float64 `eos:"ns.fusex.clients"`
+	Fusex_lockedclients                        *float64 `eos:"ns.fusex.lockedclients"`
+	Hanging_since                              *float64 `eos:"ns.hanging.since"`
+	Latency_dirs                               *float64 `eos:"ns.latency.dirs"`
+	Latency_files                              *float64 `eos:"ns.latency.files"`
+	Latency_pending_updates                    *float64 `eos:"ns.latency.pending.updates"`
+	Latencypeak_eosviewmutex_1min              *float64 `eos:"ns.latencypeak.eosviewmutex.1min"`
+	Latencypeak_eosviewmutex_2min              *float64 `eos:"ns.latencypeak.eosviewmutex.2min"`
+	Latencypeak_eosviewmutex_5min              *float64 `eos:"ns.latencypeak.eosviewmutex.5min"`
+	Latencypeak_eosviewmutex_last              *float64 `eos:"ns.latencypeak.eosviewmutex.last"`
+	Qclient_rtt_ms_min                         *float64 `eos:"ns.qclient.rtt_ms.min"`
+	Qclient_rtt_ms_avg                         *float64 `eos:"ns.qclient.rtt_ms.avg"`
+	Qclient_rtt_ms_max                         *float64 `eos:"ns.qclient.rtt_ms.max"`
+	Qclient_rtt_ms_peak_1min                   *float64 `eos:"ns.qclient.rtt_ms_peak.1min"`
+	Qclient_rtt_ms_peak_2min                   *float64 `eos:"ns.qclient.rtt_ms_peak.2min"`
+	Qclient_rtt_ms_peak_5min                   *float64 `eos:"ns.qclient.rtt_ms_peak.5min"`
+	Memory_growth                              *float64 `eos:"ns.memory.growth"`
+	Memory_resident                            *float64 `eos:"ns.memory.resident"`
+	Memory_share                               *float64 `eos:"ns.memory.share"`
+	Memory_virtual                             *float64 `eos:"ns.memory.virtual"`
+	Stat_threads                               *float64 `eos:"ns.stat.threads"`
+	Total_directories                          *float64 `eos:"ns.total.directories"`
+	Total_directories_changelog_avg_entry_size *float64 `eos:"ns.total.directories.changelog.avg_entry_size"`
+	Total_directories_changelog_size           *float64 `eos:"ns.total.directories.changelog.size"`
+	Total_files                                *float64 `eos:"ns.total.files"`
+	Total_files_changelog_avg_entry_size       *float64 `eos:"ns.total.files.changelog.avg_entry_size"`
+	Total_files_changelog_size                 *float64 `eos:"ns.total.files.changelog.size"`
+	Uptime                                     *float64 `eos:"ns.uptime"`
+	Cache_files_requests                       *float64 `eos:"ns.cache.files.requests"`
+	Cache_files_hits                           *float64 `eos:"ns.cache.files.hits"`
+	Cache_containers_requests                  *float64 `eos:"ns.cache.containers.requests"`
+	Cache_containers_hits                      *float64 `eos:"ns.cache.containers.hits"`
+	Traffic_shaping_enabled                    *bool    `eos:"-"`
 }
 
 type NSActivityInfo struct {
@@ -333,42 +351,64 @@ type NodeLSResponse struct {
 	Result   []*NodeLS `json:"result"`
 }
 
+// New creates a client. It is meant to be long-lived and shared: its logger in
+// particular is created once here.
 func New(opt *Options) (*Client, error) {
-	opt.init()
+	if err := opt.init(); err != nil {
+		return nil, err
+	}
 	c := new(Client)
 	c.opt = opt
 	return c, nil
 }
 
+// WithExecutor returns a client sharing the options of c, its logger and its
+// timeout in particular, but running the eos commands through executor
+func (c *Client) WithExecutor(executor Executor) *Client {
+	opt := *c.opt
+	opt.Executor = executor
+	return &Client{opt: &opt}
+}
+
 func getUnixUser(username string) (*osuser.User, error) {
 	return osuser.Lookup(username)
 }
 
-// exec executes the command and returns the stdout, stderr and return code
-func (c *Client) execute(cmd *exec.Cmd) (string, string, error) {
-	outBuf := &bytes.Buffer{}
-	errBuf := &bytes.Buffer{}
-	cmd.Stdout = outBuf
-	cmd.Stderr = errBuf
-	err := cmd.Run()
-	if c.opt.EnableLogging {
-		c.opt.Logger.Info("eosclient", zap.String("cmd", fmt.Sprintf("%+v", cmd)))
-	}
-
-	if exiterr, ok := err.(*exec.ExitError); ok {
-		// The program has exited with an exit code != 0
-		// This works on both Unix and Windows. Although package
-		// syscall is generally platform dependent, WaitStatus is
-		// defined for both Unix and Windows and in both cases has
-		// an ExitStatus() method with the same signature.
-		if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
-			switch status.ExitStatus() {
-			case 2:
-				err = fmt.Errorf("error: FIXME") // api.NewError(api.StorageNotFoundErrorCode)
-			}
+// execute runs the eos command with the given arguments through the configured
+// Executor and returns the stdout, stderr and error, a *CommandError if the command failed
+func (c *Client) execute(ctx context.Context, args ...string) (string, string, error) {
+	args = c.withURL(args)
+	start := time.Now()
+	stdout, stderr, err := c.opt.Executor.Execute(ctx, args...)
+	if err != nil && !errors.Is(err, ErrCircuitOpen) {
+		err = commandError(args, err, stderr, errors.Is(context.Cause(ctx), context.DeadlineExceeded))
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		c.opt.Metrics.observeCommand(args, start, err)
+		if c.opt.Trace != nil {
+			c.opt.Trace.observe(args, start, stdout, stderr, err)
 		}
 	}
-	return outBuf.String(), errBuf.String(), err
+	if c.opt.EnableLogging {
+		c.opt.Logger.Info("eosclient", zap.Strings("args", args))
+	}
+	return stdout, stderr, err
+}
+
+// withURL inserts the MGM URL in the arguments of an eos command, after the role if any:
+// eos [-r <uid> <gid>] [<url>] <command>
+func (c *Client) withURL(args []string) []string {
+	if c.opt.URL == "" {
+		return args
+	}
+	n := 0
+	if len(args) >= 3 && args[0] == "-r" {
+		n = 3
+	}
+	res := make([]string, 0, len(args)+1)
+	res = append(res, args[:n]...)
+	res = append(res, c.opt.URL)
+	return append(res, args[n:]...)
 }
 
 func (c *Client) getTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
@@ -376,6 +416,15 @@ func (c *Client) getTimeout(ctx context.Context) (context.Context, context.Cance
 	return context.WithTimeout(ctx, time.Duration(timeout))
 }
 
+// Ping checks that the MGM answers a cheap command within the timeout
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := c.getTimeout(ctx)
+	defer cancel()
+
+	_, _, err := c.execute(ctx, "whoami")
+	return err
+}
+
 // List the nodes on the instance
 func (c *Client) ListNode(ctx context.Context, username string) ([]*NodeInfo, error) {
 	unixUser, err := getUnixUser(username)
@@ -386,12 +435,15 @@ func (c *Client) ListNode(ctx context.Context, username string) ([]*NodeInfo, er
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "node", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "node", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseNodesInfo(stdout)
+	res, err := c.parseNodesInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "node", "ls", "-m")
+	}
+	return res, nil
 }
 
 // List the scheduling groups on the instance
@@ -404,12 +456,15 @@ func (c *Client) ListGroup(ctx context.Context, username string) ([]*GroupInfo,
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "group", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "group", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseGroupsInfo(stdout)
+	res, err := c.parseGroupsInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "group", "ls", "-m")
+	}
+	return res, nil
 }
 
 // List the filesystems on the instance
@@ -422,52 +477,71 @@ func (c *Client) ListFS(ctx context.Context, username string) ([]*FSInfo, error)
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "fs", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "fs", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseFSsInfo(stdout)
+	res, err := c.parseFSsInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "fs", "ls", "-m")
+	}
+	return res, nil
 }
 
 // List the activity of different users in the instance
 func (c *Client) ListNS(ctx context.Context) ([]*NSInfo, []*NSActivityInfo, []*NSBatchInfo, error) {
+	unixUser, err := getUnixUser("root")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	stdoutHuman, stderrHuman, errHuman := c.execute(exec.CommandContext(ctxWt, "/usr/bin/eos", "ns", "stat"))
+	stdoutHuman, _, errHuman := c.execute(ctxWt, "ns", "stat")
 	if errHuman != nil {
 		// Older EOS versions may not expose traffic shaping details in `eos ns stat`.
 		// Keep namespace metrics available and simply omit the shaping-enabled gauge.
-		c.opt.Logger.Info("optional eos ns stat failed, skipping traffic shaping status", zap.Error(errHuman), zap.String("stderr", strings.TrimSpace(stderrHuman)))
+		c.opt.Logger.Info("optional eos ns stat failed, skipping traffic shaping status", zap.Error(errHuman))
 		stdoutHuman = ""
 	}
 
 	// eos ns stat, without -a will exclude batch users info (this adds to much latency in the instance where the exporter is deployed)
-	stdout, stderr, err := c.execute(exec.CommandContext(ctxWt, "/usr/bin/eos", "ns", "stat", "-m"))
+	stdout, _, err := c.execute(ctxWt, "ns", "stat", "-m")
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("eos ns stat -m failed: %w (stderr: %s)", err, strings.TrimSpace(stderr))
+		return nil, nil, nil, err
 	}
 
-	stdo, stderrWho, err2 := c.execute(exec.CommandContext(ctxWt, "/usr/bin/eos", "who", "-a", "-m"))
+	// Run with the role of root, like Who does for the who collector, so that both
+	// share the same command line and a scrape runs eos who -a -m once
+	stdo, _, err2 := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "who", "-a", "-m")
 	if err2 != nil {
-		return nil, nil, nil, fmt.Errorf("eos who -a -m failed: %w (stderr: %s)", err2, strings.TrimSpace(stderrWho))
+		return nil, nil, nil, err2
 	}
 
-	return c.parseNSsInfo(stdout, stdo, stdoutHuman, ctx)
+	ns, activity, batch, err := c.parseNSsInfo(stdout, stdo, stdoutHuman, ctx)
+	if err != nil {
+		return nil, nil, nil, c.parseError(err, "ns", "stat", "-m")
+	}
+	return ns, activity, batch, nil
 }
 
 // List the IO info in the instance
 func (c *Client) ListIOInfo(ctx context.Context) ([]*IOInfo, error) {
 
-	ctx, _ = c.getTimeout(ctx)
+	ctx, cancel := c.getTimeout(ctx)
+	defer cancel()
 
-	stdout1, _, err := c.execute(exec.CommandContext(ctx, "/usr/bin/eos", "io", "stat", "-m"))
+	stdout1, _, err := c.execute(ctx, "io", "stat", "-m")
 	if err != nil {
 		return nil, err
 	}
 
-	return c.parseIOInfosInfo(stdout1, ctx)
+	res, err := c.parseIOInfosInfo(stdout1, ctx)
+	if err != nil {
+		return nil, c.parseError(err, "io", "stat", "-m")
+	}
+	return res, nil
 }
 
 // List the IO info in the instance
@@ -476,12 +550,16 @@ func (c *Client) ListIOAppInfo(ctx context.Context) ([]*IOInfo, error) {
 	ctx, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	stdout2, _, err := c.execute(exec.CommandContext(ctx, "/usr/bin/eos", "io", "stat", "-m", "-x"))
+	stdout2, _, err := c.execute(ctx, "io", "stat", "-m", "-x")
 	if err != nil {
 		return nil, err
 	}
 
-	return c.parseIOAppInfosInfo(stdout2, ctx)
+	res, err := c.parseIOAppInfosInfo(stdout2, ctx)
+	if err != nil {
+		return nil, c.parseError(err, "io", "stat", "-m", "-x")
+	}
+	return res, nil
 }
 
 func getHostname(hostport string) (string, string, bool) {
@@ -548,38 +626,13 @@ func (c *Client) parseNodesInfo(raw string) ([]*NodeInfo, error) {
 
 // Gathers information of one single node
 func (c *Client) parseNodeInfo(line string) (*NodeInfo, error) {
-	//kv := make(map[string]string)
-	kv := c.getMap(line)
-	host, port, foundcolon := getHostname(kv["hostport"])
+	fst := &NodeInfo{}
+	c.decode(line, fst)
+	host, port, foundcolon := getHostname(fst.HostPort)
 	if !foundcolon {
-		return nil, fmt.Errorf("bad hostport: %s", kv["hostport"])
-	}
-	fst := &NodeInfo{
-		Host:                  host,
-		Port:                  port,
-		Status:                kv["status"],
-		CfgStatus:             kv["cfg.status"],
-		Nofs:                  kv["nofs"],
-		HeartBeatDelta:        kv["heartbeatdelta"],
-		SumStatStatfsFree:     kv["sum.stat.statfs.freebytes"],
-		SumStatStatfsUsed:     kv["sum.stat.statfs.usedbytes"],
-		SumStatStatfsTotal:    kv["sum.stat.statfs.capacity"],
-		SumStatStatFilesFree:  kv["sum.stat.statfs.ffree"],
-		SumStatStatFilesUsed:  kv["sum.stat.usedfiles"],
-		SumStatStatFilesTotal: kv["sum.stat.statfs.files"],
-		SumStatRopen:          kv["sum.stat.ropen"],
-		SumStatWopen:          kv["sum.stat.wopen"],
-		CfgStatSysThreads:     kv["cfg.stat.sys.threads"],
-		CfgStatSysVsize:       kv["cfg.stat.sys.vsize"],
-		CfgStatSysRss:         kv["cfg.stat.sys.rss"],
-		CfgStatSysSockets:     kv["cfg.stat.sys.sockets"],
-		SumStatNetInratemib:   kv["sum.stat.net.inratemib"],
-		SumStatNetOutratemib:  kv["sum.stat.net.outratemib"],
-		EOSVersion:            kv["cfg.stat.sys.eos.version"],
-		XRootDVersion:         kv["cfg.stat.sys.xrootd.version"],
-		Kernel:                kv["cfg.stat.sys.kernel"],
-		Geotag:                kv["cfg.stat.geotag"],
+		return nil, fmt.Errorf("bad hostport: %s", fst.HostPort)
 	}
+	fst.Host, fst.Port = host, port
 	return fst, nil
 }
 
@@ -603,34 +656,8 @@ func (c *Client) parseGroupsInfo(raw string) ([]*GroupInfo, error) {
 
 // Gathers information of one single group
 func (c *Client) parseGroupInfo(line string) (*GroupInfo, error) {
-	//kv := make(map[string]string)
-	kv := c.getMap(line)
-	group := &GroupInfo{
-		kv["name"],
-		kv["cfg.status"],
-		kv["nofs"],
-		kv["avg.stat.disk.load"],
-		kv["sig.stat.disk.load"],
-		kv["sum.stat.disk.readratemb"],
-		kv["sum.stat.disk.writeratemb"],
-		kv["sum.stat.net.ethratemib"],
-		kv["sum.stat.net.inratemib"],
-		kv["sum.stat.net.outratemib"],
-		kv["sum.stat.ropen"],
-		kv["sum.stat.wopen"],
-		kv["sum.stat.statfs.usedbytes"],
-		kv["sum.stat.statfs.freebytes"],
-		kv["sum.stat.statfs.capacity"],
-		kv["sum.stat.usedfiles"],
-		kv["sum.stat.statfs.ffree"],
-		kv["sum.stat.statfs.files"],
-		kv["dev.stat.statfs.filled"],
-		kv["avg.stat.statfs.filled"],
-		kv["sig.stat.statfs.filled"],
-		kv["cfg.stat.balancing"],
-		kv["sum.stat.balancer.running"],
-		kv["sum.stat.drainer.running"],
-	}
+	group := &GroupInfo{}
+	c.decode(line, group)
 	return group, nil
 }
 
@@ -654,55 +681,8 @@ func (c *Client) parseFSsInfo(raw string) ([]*FSInfo, error) {
 
 // Gathers information of one single filesystem
 func (c *Client) parseFSInfo(line string) (*FSInfo, error) {
-	//kv := make(map[string]string)
-	kv := c.getMap(line)
-	fs := &FSInfo{
-		kv["host"],
-		kv["port"],
-		kv["id"],
-		kv["uuid"],
-		kv["path"],
-		kv["schedgroup"],
-		kv["stat.boot"],
-		kv["configstatus"],
-		kv["headroom"],
-		kv["stat.errc"],
-		kv["stat.errmsg"],
-		kv["stat.disk.load"],
-		kv["stat.disk.readratemb"],
-		kv["stat.disk.writeratemb"],
-		kv["stat.net.ethratemib"],
-		kv["stat.net.inratemib"],
-		kv["stat.net.outratemib"],
-		kv["stat.ropen"],
-		kv["stat.wopen"],
-		kv["stat.statfs.freebytes"],
-		kv["stat.statfs.usedbytes"],
-		kv["stat.statfs.capacity"],
-		kv["stat.usedfiles"],
-		kv["stat.statfs.ffree"],
-		kv["stat.statfs.fused"],
-		kv["stat.statfs.files"],
-		kv["drainstatus"],
-		kv["stat.drainprogress"],
-		kv["stat.drainfiles"],
-		kv["stat.drainbytesleft"],
-		kv["stat.drainretry"],
-		kv["stat.drain.failed"],
-		kv["graceperiod"],
-		kv["stat.timeleft"],
-		kv["stat.active"],
-		kv["stat.balancer.running"],
-		kv["stat.drainer.running"],
-		kv["stat.disk.iops"],
-		kv["stat.disk.bw"],
-		kv["stat.geotag"],
-		kv["stat.health"],
-		kv["stat.health.redundancy_factor"],
-		kv["stat.health.drives_failed"],
-		kv["stat.health.drives_total"],
-		kv["stat.health.indicator"],
-	}
+	fs := &FSInfo{}
+	c.decode(line, fs)
 	return fs, nil
 }
 
@@ -734,7 +714,9 @@ func isInMap(a string, m map[string]int) bool {
 	return false
 }
 
-func parseNSTrafficShapingEnabled(raw string) string {
+// parseNSTrafficShapingEnabled returns is_enabled of the traffic shaping info
+// of eos ns stat, nil when it is not found
+func parseNSTrafficShapingEnabled(raw string) *bool {
 	for _, line := range strings.Split(raw, "\n") {
 		lower := strings.ToLower(line)
 		if !strings.Contains(lower, "traffic shaping info") {
@@ -755,9 +737,13 @@ func parseNSTrafficShapingEnabled(raw string) string {
 		if len(fields) > 0 {
 			value = fields[0]
 		}
-		return strings.Trim(value, ",;")
+		enabled, err := parseBool(strings.Trim(value, ",;"))
+		if err != nil {
+			return nil
+		}
+		return &enabled
 	}
-	return ""
+	return nil
 }
 
 // Gathers information of the namespace
@@ -857,54 +843,9 @@ func (c *Client) parseNSsInfo(raw string, raw_batch string, raw_human string, ct
 				if len(kv) <= 3 {
 					for k := range kv {
 						if k != "uid" && k != "gid" {
-							if _, err := strconv.ParseFloat(kv[k], 64); err != nil {
-								fmt.Sprintf("Value of '%s': '%s' is not floatable", k, kv[k])
-							}
-							nsinfo = &NSInfo{
-								kv["ns.boot.file.time"],
-								kv["ns.boot.status"],
-								kv["ns.boot.time"],
-								kv["ns.cache.containers.maxsize"],
-								kv["ns.cache.containers.occupancy"],
-								kv["ns.cache.files.maxsize"],
-								kv["ns.cache.files.occupancy"],
-								kv["ns.fds.all"],
-								kv["ns.fusex.activeclients"],
-								kv["ns.fusex.caps"],
-								kv["ns.fusex.clients"],
-								kv["ns.fusex.lockedclients"],
-								kv["ns.hanging.since"],
-								kv["ns.latency.dirs"],
-								kv["ns.latency.files"],
-								kv["ns.latency.pending.updates"],
-								kv["ns.latencypeak.eosviewmutex.1min"],
-								kv["ns.latencypeak.eosviewmutex.2min"],
-								kv["ns.latencypeak.eosviewmutex.5min"],
-								kv["ns.latencypeak.eosviewmutex.last"],
-								kv["ns.qclient.rtt_ms.min"],
-								kv["ns.qclient.rtt_ms.avg"],
-								kv["ns.qclient.rtt_ms.max"],
-								kv["ns.qclient.rtt_ms_peak.1min"],
-								kv["ns.qclient.rtt_ms_peak.2min"],
-								kv["ns.qclient.rtt_ms_peak.5min"],
-								kv["ns.memory.growth"],
-								kv["ns.memory.resident"],
-								kv["ns.memory.share"],
-								kv["ns.memory.virtual"],
-								kv["ns.stat.threads"],
-								kv["ns.total.directories"],
-								kv["ns.total.directories.changelog.avg_entry_size"],
-								kv["ns.total.directories.changelog.size"],
-								kv["ns.total.files"],
-								kv["ns.total.files.changelog.avg_entry_size"],
-								kv["ns.total.files.changelog.size"],
-								kv["ns.uptime"],
-								kv["ns.cache.files.requests"],
-								kv["ns.cache.files.hits"],
-								kv["ns.cache.containers.requests"],
-								kv["ns.cache.containers.hits"],
-								trafficShapingEnabled,
-							}
+							// one stat per line, so that the other keys are missing
+							nsinfo = &NSInfo{Traffic_shaping_enabled: trafficShapingEnabled}
+							c.decodeStat(map[string]string{k: kv[k]}, nsinfo)
 						}
 					}
 				}
@@ -917,7 +858,7 @@ func (c *Client) parseNSsInfo(raw string, raw_batch string, raw_human string, ct
 			ctx, cancel := c.getTimeout(ctx)
 			defer cancel()
 
-			stdo, _, err := c.execute(exec.CommandContext(ctx, "eos", "version"))
+			stdo, _, err := c.execute(ctx, "version")
 			if err != nil {
 				fmt.Println("Couldn't get the EOS instance")
 			}
@@ -938,11 +879,11 @@ func (c *Client) parseNSsInfo(raw string, raw_batch string, raw_human string, ct
 			touch_lat, err := strconv.ParseFloat(strings.TrimRight(strings.Split(parse_latency[3], ", ")[1], "))"), 32)
 			ls_lat, err := strconv.ParseFloat(strings.TrimRight(strings.Split(parse_latency[9], ", ")[1], "))"), 32)
 
-			stdout, _, err := c.execute(exec.CommandContext(ctx, "id", kv["uid"]))
+			stdout, err := exec.CommandContext(ctx, "id", kv["uid"]).Output()
 			if err != nil {
 				fmt.Printf("Couldn't get the uid of %s\n", kv["uid"])
 			} else {
-				kv["uid"] = strings.Split(strings.TrimLeft(stdout, "uid="), "(")[0]
+				kv["uid"] = strings.Split(strings.TrimLeft(string(stdout), "uid="), "(")[0]
 			}
 			/*// For testing
 			fmt.Printf("Uid: %s: cmd: %s, total: %s\n", kv["uid"], kv["cmd"], kv["total"])
@@ -1057,10 +998,10 @@ func (c *Client) parseAppIOInfo(line string) (*IOInfo, error) {
 
 // Data struct //
 type RecycleInfo struct {
-	UsedBytes string
-	MaxBytes  string
-	Lifetime  string
-	Ratio     string
+	UsedBytes *float64 `eos:"usedbytes"`
+	MaxBytes  *float64 `eos:"maxbytes"`
+	Lifetime  *float64 `eos:"lifetime"` // seconds
+	Ratio     *float64 `eos:"ratio"`
 }
 
 // Launch recycle command //
@@ -1073,12 +1014,15 @@ func (c *Client) Recycle(ctx context.Context, username string) ([]*RecycleInfo,
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "recycle", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "recycle", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseRecycleInfo(stdout)
+	res, err := c.parseRecycleInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "recycle", "-m")
+	}
+	return res, nil
 }
 
 // Parse information from recycle bin //
@@ -1099,13 +1043,8 @@ func (c *Client) parseRecycleInfo(raw string) ([]*RecycleInfo, error) {
 }
 
 func (c *Client) parseRecycleLineInfo(line string) (*RecycleInfo, error) {
-	kv := c.getMap(line)
-	rb := &RecycleInfo{
-		kv["usedbytes"],
-		kv["maxbytes"],
-		kv["lifetime"],
-		kv["ratio"],
-	}
+	rb := &RecycleInfo{}
+	c.decode(line, rb)
 	return rb, nil
 }
 
@@ -1131,12 +1070,15 @@ func (c *Client) Quotas(ctx context.Context, username string) ([]*QuotaInfo, err
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "quota", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "quota", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseQuotaInfo(stdout)
+	res, err := c.parseQuotaInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "quota", "ls", "-m")
+	}
+	return res, nil
 }
 
 // Parse information from recycle bin //
@@ -1222,12 +1164,15 @@ func (c *Client) Who(ctx context.Context, username string) ([]*WhoInfo, error) {
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "who", "-a", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "who", "-a", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseWhoInfo(stdout)
+	res, err := c.parseWhoInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "who", "-a", "-m")
+	}
+	return res, nil
 }
 
 // Parse information from recycle bin //
@@ -1263,37 +1208,37 @@ func (c *Client) parseWhoInfo(raw string) ([]*WhoInfo, error) {
 
 // struct definition
 type SpaceInfo struct {
-	Type                                 string
-	Name                                 string
-	CfgGroupSize                         string
-	CfgGroupMod                          string
-	Nofs                                 string
-	AvgStatDiskLoad                      string
-	SigStatDiskLoad                      string
-	SumStatDiskReadratemb                string
-	SumStatDiskWriteratemb               string
-	SumStatNetEthratemib                 string
-	SumStatNetInratemib                  string
-	SumStatNetOutratemib                 string
-	SumStatRopen                         string
-	SumStatWopen                         string
-	SumStatStatfsUsedbytes               string
-	SumStatStatfsFreebytes               string
-	SumStatStatfsCapacity                string
-	SumStatUsedfiles                     string
-	SumStatStatfsFfiles                  string
-	SumStatStatfsFiles                   string
-	SumStatStatfsCapacityConfigstatusRw  string
-	SumNofsConfigstatusRw                string
-	CfgQuota                             string
-	CfgNominalsize                       string
-	CfgBalancer                          string
-	CfgBalancerThreshold                 string
-	SumStatBalancerRunning               string
-	SumStatDrainerRunning                string
-	SumStatDiskIopsConfigstatusRw        string
-	SumStatDiskBwConfigstatusRw          string
-	SumStatStatfsFreebytesConfigstatusRw string
+	Type                                 string   `eos:"type"`
+	Name                                 string   `eos:"name"`
+	CfgGroupSize                         *float64 `eos:"cfg.groupsize"`
+	CfgGroupMod                          *float64 `eos:"cfg.groupmod"`
+	Nofs                                 *float64 `eos:"nofs"`
+	AvgStatDiskLoad                      *float64 `eos:"avg.stat.disk.load"`
+	SigStatDiskLoad                      *float64 `eos:"sig.stat.disk.load"`
+	SumStatDiskReadratemb                *float64 `eos:"sum.stat.disk.readratemb"`
+	SumStatDiskWriteratemb               *float64 `eos:"sum.stat.disk.writeratemb"`
+	SumStatNetEthratemib                 *float64 `eos:"sum.stat.net.ethratemib"`
+	SumStatNetInratemib                  *float64 `eos:"sum.stat.net.inratemib"`
+	SumStatNetOutratemib                 *float64 `eos:"sum.stat.net.outratemib"`
+	SumStatRopen                         *float64 `eos:"sum.stat.ropen"`
+	SumStatWopen                         *float64 `eos:"sum.stat.wopen"`
+	SumStatStatfsUsedbytes               *float64 `eos:"sum.stat.statfs.usedbytes"`
+	SumStatStatfsFreebytes               *float64 `eos:"sum.stat.statfs.freebytes"`
+	SumStatStatfsCapacity                *float64 `eos:"sum.stat.statfs.capacity"`
+	SumStatUsedfiles                     *float64 `eos:"sum.stat.usedfiles"`
+	SumStatStatfsFfiles                  *int64   `eos:"sum.stat.statfs.ffiles"`
+	SumStatStatfsFiles                   *float64 `eos:"sum.stat.statfs.files"`
+	SumStatStatfsCapacityConfigstatusRw  *float64 `eos:"sum.stat.statfs.capacity?configstatus@rw"`
+	SumNofsConfigstatusRw                *float64 `eos:"sum.<n>?configstatus@rw"`
+	CfgQuota                             bool     `eos:"cfg.quota"`
+	CfgNominalsize                       *float64 `eos:"cfg.nominalsize"`
+	CfgBalancer                          bool     `eos:"cfg.balancer"`
+	CfgBalancerThreshold                 *float64 `eos:"cfg.balancer.threshold"`
+	SumStatBalancerRunning               *float64 `eos:"sum.stat.balancer.running"`
+	SumStatDrainerRunning                *float64 `eos:"sum.stat.drainer.running"`
+	SumStatDiskIopsConfigstatusRw        *float64 `eos:"sum.stat.disk.iops?configstatus@rw"`
+	SumStatDiskBwConfigstatusRw          *float64 `eos:"sum.stat.disk.bw?configstatus@rw"`
+	SumStatStatfsFreebytesConfigstatusRw *float64 `eos:"sum.stat.statfs.freebytes?configstatus@rw"`
 }
 
 // List the spaces on the instance
@@ -1306,12 +1251,15 @@ func (c *Client) ListSpace(ctx context.Context, username string) ([]*SpaceInfo,
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "space", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "space", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseSpacesInfo(stdout)
+	res, err := c.parseSpacesInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "space", "ls", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all spaces.
@@ -1334,41 +1282,8 @@ func (c *Client) parseSpacesInfo(raw string) ([]*SpaceInfo, error) {
 
 // Gathers information of one single space
 func (c *Client) parseSpaceInfo(line string) (*SpaceInfo, error) {
-	//kv := make(map[string]string)
-	kv := c.getMap(line)
-	space := &SpaceInfo{
-		kv["type"],
-		kv["name"],
-		kv["cfg.groupsize"],
-		kv["cfg.groupmod"],
-		kv["nofs"],
-		kv["avg.stat.disk.load"],
-		kv["sig.stat.disk.load"],
-		kv["sum.stat.disk.readratemb"],
-		kv["sum.stat.disk.writeratemb"],
-		kv["sum.stat.net.ethratemib"],
-		kv["sum.stat.net.inratemib"],
-		kv["sum.stat.net.outratemib"],
-		kv["sum.stat.ropen"],
-		kv["sum.stat.wopen"],
-		kv["sum.stat.statfs.usedbytes"],
-		kv["sum.stat.statfs.freebytes"],
-		kv["sum.stat.statfs.capacity"],
-		kv["sum.stat.usedfiles"],
-		kv["sum.stat.statfs.ffiles"],
-		kv["sum.stat.statfs.files"],
-		kv["sum.stat.statfs.capacity?configstatus@rw"],
-		kv["sum.<n>?configstatus@rw"],
-		kv["cfg.quota"],
-		kv["cfg.nominalsize"],
-		kv["cfg.balancer"],
-		kv["cfg.balancer.threshold"],
-		kv["sum.stat.balancer.running"],
-		kv["sum.stat.drainer.running"],
-		kv["sum.stat.disk.iops?configstatus@rw"],
-		kv["sum.stat.disk.bw?configstatus@rw"],
-		kv["sum.stat.statfs.freebytes?configstatus@rw"],
-	}
+	space := &SpaceInfo{}
+	c.decode(line, space)
 	return space, nil
 }
 
@@ -1395,13 +1310,16 @@ func (c *Client) FsckReport(ctx context.Context, username string) ([]*FsckInfo,
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	//cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "fsck", "report", "-a")
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "fsck", "stat")
-	stdout, _, err := c.execute(cmd)
+	//stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "fsck", "report", "-a")
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "fsck", "stat")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseFsckInfo(stdout)
+	res, err := c.parseFsckInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "fsck", "stat")
+	}
+	return res, nil
 }
 
 // Parse information from fsck report //
@@ -1454,12 +1372,15 @@ func (c *Client) ListFusex(ctx context.Context, username string) ([]*FusexInfo,
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "fusex", "ls", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "fusex", "ls", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseFusexsInfo(stdout)
+	res, err := c.parseFusexsInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "fusex", "ls", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all fusexs.
@@ -1626,12 +1547,15 @@ func (c *Client) ListInspectorLayout(ctx context.Context, username string) ([]*I
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorLayoutsInfo(stdout)
+	res, err := c.parseInspectorLayoutsInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1675,12 +1599,15 @@ func (c *Client) ListInspectorAccessTimeVolume(ctx context.Context, username str
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorAccessTimeVolumeInfo(stdout)
+	res, err := c.parseInspectorAccessTimeVolumeInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1721,12 +1648,15 @@ func (c *Client) ListInspectorAccessTimeFiles(ctx context.Context, username stri
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorAccessTimeFilesInfo(stdout)
+	res, err := c.parseInspectorAccessTimeFilesInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1768,12 +1698,15 @@ func (c *Client) ListInspectorBirthTimeVolume(ctx context.Context, username stri
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorBirthTimeVolumeInfo(stdout)
+	res, err := c.parseInspectorBirthTimeVolumeInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1814,12 +1747,15 @@ func (c *Client) ListInspectorBirthTimeFiles(ctx context.Context, username strin
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorBirthTimeFilesInfo(stdout)
+	res, err := c.parseInspectorBirthTimeFilesInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1860,12 +1796,15 @@ func (c *Client) ListInspectorGroupCostDisk(ctx context.Context, username string
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorGroupCostDiskInfo(stdout)
+	res, err := c.parseInspectorGroupCostDiskInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -1907,12 +1846,15 @@ func (c *Client) ListInspectorGroupCostDiskTBYears(ctx context.Context, username
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
 	if err != nil {
 		return nil, err
 	}
-	return c.parseInspectorGroupCostDiskTBYearsInfo(stdout)
+	res, err := c.parseInspectorGroupCostDiskTBYearsInfo(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "-r", unixUser.Uid, unixUser.Gid, "inspector", "-m")
+	}
+	return res, nil
 }
 
 // Gathers the information of all lines.
@@ -2079,22 +2021,20 @@ func (c *Client) ListIOShaping(ctx context.Context, windowTimeSeconds int) ([]*I
 
 	for _, flag := range groupFlags {
 		// Appended "--sys" to ensure the system object is included in the JSON array
-		cmd := exec.CommandContext(
+		stdout, _, err := c.execute(
 			ctxWt,
-			"/usr/bin/eos", "io", "shaping", "ls",
+			"io", "shaping", "ls",
 			"--json", "--sys",
 			"--window", strconv.Itoa(windowTimeSeconds),
 			flag,
 		)
-
-		stdout, _, err := c.execute(cmd)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch shaping stats for %s: %w", flag, err)
 		}
 
 		parsed, err := c.parseIOShaping(stdout)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse shaping stats for %s: %w", flag, err)
+			return nil, c.parseError(err, "io", "shaping", "ls", "--json", "--sys", "--window", strconv.Itoa(windowTimeSeconds), flag)
 		}
 
 		allStats = append(allStats, parsed...)
@@ -2150,13 +2090,16 @@ func (c *Client) ListIOShapingFS(ctx context.Context) ([]*IOShapingFSStat, error
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "io", "shaping", "ls", "--fs", "--json")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "io", "shaping", "ls", "--fs", "--json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch filesystem shaping stats: %w", err)
 	}
 
-	return c.parseIOShapingFS(stdout)
+	res, err := c.parseIOShapingFS(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "io", "shaping", "ls", "--fs", "--json")
+	}
+	return res, nil
 }
 
 func (c *Client) parseIOShapingFS(raw string) ([]*IOShapingFSStat, error) {
@@ -2199,19 +2142,22 @@ func (c *Client) ListIOShapingAll(ctx context.Context, windowTimeSeconds int) ([
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(
+	stdout, _, err := c.execute(
 		ctxWt,
-		"/usr/bin/eos", "io", "shaping", "ls",
+		"io", "shaping", "ls",
 		"--all", "--sys",
 		"--window", strconv.Itoa(windowTimeSeconds),
 		"--json",
 	)
-	stdout, _, err := c.execute(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch all-tags shaping stats for window %ds: %w", windowTimeSeconds, err)
 	}
 
-	return c.parseIOShapingAll(stdout)
+	res, err := c.parseIOShapingAll(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "io", "shaping", "ls", "--all", "--sys", "--window", strconv.Itoa(windowTimeSeconds), "--json")
+	}
+	return res, nil
 }
 
 func (c *Client) parseIOShapingAll(raw string) ([]*IOShapingAllStat, error) {
@@ -2314,21 +2260,23 @@ func (c *Client) ListIOShapingConfig(ctx context.Context) (*IOShapingConfig, err
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "io", "shaping", "config", "ls", "--json")
-	stdout, stderr, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "io", "shaping", "config", "ls", "--json")
 	if err == nil {
-		return c.parseIOShapingConfig(stdout)
+		config, err := c.parseIOShapingConfig(stdout)
+		if err != nil {
+			return nil, c.parseError(err, "io", "shaping", "config", "ls", "--json")
+		}
+		return config, nil
 	}
 
-	textCmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "io", "shaping", "config", "ls")
-	textStdout, textStderr, textErr := c.execute(textCmd)
+	textStdout, _, textErr := c.execute(ctxWt, "io", "shaping", "config", "ls")
 	if textErr != nil {
-		return nil, fmt.Errorf("failed to fetch shaping config as json: %w (stderr: %s); text fallback failed: %w (stderr: %s)", err, strings.TrimSpace(stderr), textErr, strings.TrimSpace(textStderr))
+		return nil, fmt.Errorf("failed to fetch shaping config as json: %w; text fallback failed: %w", err, textErr)
 	}
 
 	config, parseErr := c.parseIOShapingConfigText(textStdout)
 	if parseErr != nil {
-		return nil, fmt.Errorf("failed to fetch shaping config as json: %w (stderr: %s); failed to parse text fallback: %w", err, strings.TrimSpace(stderr), parseErr)
+		return nil, fmt.Errorf("failed to fetch shaping config as json: %w; %w", err, c.parseError(parseErr, "io", "shaping", "config", "ls"))
 	}
 	return config, nil
 }
@@ -2422,13 +2370,16 @@ func (c *Client) ListIOShapingPolicies(ctx context.Context) ([]*IOShapingPolicyS
 	ctxWt, cancel := c.getTimeout(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctxWt, "/usr/bin/eos", "io", "shaping", "policy", "ls", "--json")
-	stdout, _, err := c.execute(cmd)
+	stdout, _, err := c.execute(ctxWt, "io", "shaping", "policy", "ls", "--json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch shaping policies: %w", err)
 	}
 
-	return c.parseIOShapingPolicies(stdout)
+	res, err := c.parseIOShapingPolicies(stdout)
+	if err != nil {
+		return nil, c.parseError(err, "io", "shaping", "policy", "ls", "--json")
+	}
+	return res, nil
 }
 
 func (c *Client) parseIOShapingPolicies(raw string) ([]*IOShapingPolicyStat, error) {
@@ -1,6 +1,11 @@
 package eosclient
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestParseIOShapingConfig(t *testing.T) {
 	raw := `{
@@ -133,8 +138,8 @@ func TestParseNSTrafficShapingEnabled(t *testing.T) {
 	raw := `some other line
 Traffic Shaping Info: is_enabled=true foo=bar`
 
-	if got := parseNSTrafficShapingEnabled(raw); got != "true" {
-		t.Fatalf("expected true, got %q", got)
+	if got := parseNSTrafficShapingEnabled(raw); got == nil || !*got {
+		t.Fatalf("expected true, got %v", got)
 	}
 }
 
@@ -245,3 +250,118 @@ func TestParseIOShapingAll(t *testing.T) {
 		t.Fatalf("expected write iops 2, got %q", stat.WriteIops)
 	}
 }
+
+type fixtureExecutor struct {
+	outputs map[string]string
+	calls   [][]string
+}
+
+func (e *fixtureExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	e.calls = append(e.calls, args)
+	out, ok := e.outputs[strings.Join(args, " ")]
+	if !ok {
+		return "", "unknown command", fmt.Errorf("no fixture for %q", args)
+	}
+	return out, "", nil
+}
+
+func TestListFSUsesExecutor(t *testing.T) {
+	executor := &fixtureExecutor{outputs: map[string]string{
+		"-r 0 0 fs ls -m": "host=fst-1.cern.ch port=1095 id=12 path=/data01 stat.boot=booted configstatus=rw stat.geotag=site::rack1\n",
+	}}
+
+	client, err := New(&Options{Executor: executor})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	fss, err := client.ListFS(context.Background(), "root")
+	if err != nil {
+		t.Fatalf("ListFS returned error: %v", err)
+	}
+	if len(executor.calls) != 1 {
+		t.Fatalf("expected one command, got %d", len(executor.calls))
+	}
+	if len(fss) != 1 {
+		t.Fatalf("expected one filesystem, got %d", len(fss))
+	}
+	if fss[0].Id != "12" || fss[0].Host != "fst-1.cern.ch" || fss[0].StatBoot != "booted" {
+		t.Fatalf("unexpected filesystem %+v", fss[0])
+	}
+	if fss[0].StatGeotag != "site::rack1" {
+		t.Fatalf("expected geotag site::rack1, got %q", fss[0].StatGeotag)
+	}
+}
+
+func TestNewDefaultsToCommandExecutor(t *testing.T) {
+	client, err := New(&Options{EosBinary: "/opt/eos/bin/eos"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	executor, ok := client.opt.Executor.(*CommandExecutor)
+	if !ok {
+		t.Fatalf("expected *CommandExecutor, got %T", client.opt.Executor)
+	}
+	if executor.Binary != "/opt/eos/bin/eos" {
+		t.Fatalf("expected binary /opt/eos/bin/eos, got %q", executor.Binary)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	for args, want := range map[string]string{
+		"-r 0 0 fs ls -m":                    "fs ls",
+		"-r 0 0 io shaping config ls --json": "io shaping config ls",
+		"version":                            "version",
+		"-r 0 0 who -a -m":                   "who",
+	} {
+		if got := commandName(strings.Fields(args)); got != want {
+			t.Errorf("commandName(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+func TestExecutePassesURL(t *testing.T) {
+	executor := &fixtureExecutor{outputs: map[string]string{
+		"-r 0 0 root://mgm.example.org fs ls -m": "",
+		"root://mgm.example.org version":         "EOS_SERVER_VERSION=5.2.0 EOS_SERVER_RELEASE=1\n",
+	}}
+
+	client, err := New(&Options{URL: "root://mgm.example.org", Executor: executor})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, err := client.ListFS(context.Background(), "root"); err != nil {
+		t.Fatalf("ListFS returned error: %v", err)
+	}
+	if _, _, err := client.execute(context.Background(), "version"); err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+}
+
+func TestListFSDecimalValues(t *testing.T) {
+	executor := &fixtureExecutor{outputs: map[string]string{
+		"-r 0 0 fs ls -m": "host=fst-1.cern.ch port=1095 id=12 stat.statfs.usedbytes=1099511627776.00 stat.ropen=2.5 stat.drainretry=1e+02\n",
+	}}
+	client, err := New(&Options{Executor: executor})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	fss, err := client.ListFS(context.Background(), "root")
+	if err != nil || len(fss) != 1 {
+		t.Fatalf("ListFS returned %v, %v", fss, err)
+	}
+	for name, tc := range map[string]struct {
+		got  *float64
+		want float64
+	}{
+		"stat.statfs.usedbytes": {fss[0].StatStatfsUsedbytes, 1 << 40},
+		"stat.ropen":            {fss[0].StatRopen, 2.5},
+		"stat.drainretry":       {fss[0].StatDrainretry, 100},
+	} {
+		if tc.got == nil || *tc.got != tc.want {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, tc.got)
+		}
+	}
+}
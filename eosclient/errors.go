@@ -0,0 +1,144 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Classes of the failures of the eos commands, matched with errors.Is
+var (
+	ErrTimeout             = errors.New("timed out")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrMGMUnreachable      = errors.New("MGM unreachable")
+	ErrCommandNotSupported = errors.New("command not supported")
+	ErrNotFound            = errors.New("not found")
+	ErrParse               = errors.New("unexpected output")
+)
+
+// maxStderrLen is the length of the stderr kept in a CommandError
+const maxStderrLen = 512
+
+// CommandError is the failure of an eos command. errors.Is matches both its
+// class and the underlying error.
+type CommandError struct {
+	Command  string // command line, e.g. "eos -r 0 0 fs ls -m"
+	ExitCode int    // -1 when the command did not exit, e.g. it was killed
+	Stderr   string // truncated to maxStderrLen
+	Class    error  // one of the Err* classes, nil when unknown
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	msg := e.Command + ": "
+	if e.Class != nil {
+		msg += e.Class.Error() + ": "
+	}
+	msg += e.Err.Error()
+	if e.Stderr != "" {
+		msg += " (stderr: " + e.Stderr + ")"
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CommandError) Is(target error) bool {
+	return e.Class != nil && e.Class == target
+}
+
+// commandError returns the CommandError of a command which failed with err.
+// timedOut reports whether the command was killed by a deadline.
+func commandError(args []string, err error, stderr string, timedOut bool) *CommandError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	stderr = strings.TrimSpace(stderr)
+	if len(stderr) > maxStderrLen {
+		stderr = stderr[:maxStderrLen] + "..."
+	}
+
+	return &CommandError{
+		Command:  "eos " + strings.Join(args, " "),
+		ExitCode: exitCode,
+		Stderr:   stderr,
+		Class:    classify(err, exitCode, stderr, timedOut),
+		Err:      err,
+	}
+}
+
+// parseError returns the CommandError of a command whose output could not be parsed.
+// args are the ones given to execute, the command line gets the MGM URL like it.
+func (c *Client) parseError(err error, args ...string) error {
+	args = c.withURL(args)
+	e := &CommandError{Command: "eos " + strings.Join(args, " "), Class: ErrParse, Err: err}
+	c.opt.Metrics.observeFailure(commandName(args), e)
+	return e
+}
+
+// classify guesses the class of a failure from the exit code, an errno for the
+// eos CLI, and from the stderr
+func classify(err error, exitCode int, stderr string, timedOut bool) error {
+	stderr = strings.ToLower(stderr)
+	contains := func(substrs ...string) bool {
+		for _, s := range substrs {
+			if strings.Contains(stderr, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch syscall.Errno(exitCode) {
+	case syscall.ETIMEDOUT:
+		return ErrTimeout
+	case syscall.EACCES:
+		return ErrPermissionDenied
+	case syscall.ECONNREFUSED, syscall.EHOSTUNREACH, syscall.ENETUNREACH, syscall.ENOTCONN:
+		return ErrMGMUnreachable
+	case syscall.EOPNOTSUPP, syscall.ENOSYS:
+		return ErrCommandNotSupported
+	case syscall.ENOENT:
+		return ErrNotFound
+	}
+
+	switch {
+	case timedOut || errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case contains("permission denied", "not allowed"):
+		return ErrPermissionDenied
+	case contains("connection refused", "unable to connect", "no route to host", "name or service not known"):
+		return ErrMGMUnreachable
+	case contains("unknown command", "unrecognized option", "invalid option", "not supported"):
+		return ErrCommandNotSupported
+	case contains("no such file or directory"):
+		return ErrNotFound
+	}
+	return nil
+}
+
+// className returns the value of the class label of a failure
+func className(err error) string {
+	switch {
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrPermissionDenied):
+		return "permission_denied"
+	case errors.Is(err, ErrMGMUnreachable):
+		return "mgm_unreachable"
+	case errors.Is(err, ErrCommandNotSupported):
+		return "not_supported"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrParse):
+		return "parse"
+	}
+	return "other"
+}
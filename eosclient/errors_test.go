@@ -0,0 +1,82 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommandErrorClass(t *testing.T) {
+	for script, want := range map[string]error{
+		"exit 13": ErrPermissionDenied,
+		"echo 'error: permission denied' >&2; exit 1": ErrPermissionDenied,
+		"echo 'Connection refused' >&2; exit 1":       ErrMGMUnreachable,
+		"exit 95":                                     ErrCommandNotSupported,
+		"exit 110":                                    ErrTimeout,
+		"exit 2":                                      ErrNotFound,
+		"echo 'error: no such file or directory' >&2; exit 1": ErrNotFound,
+		"exit 1": nil,
+	} {
+		cmd := exec.Command("sh", "-c", script)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		err := commandError([]string{"fs", "ls", "-m"}, cmd.Run(), stderr.String(), false)
+
+		if err.Class != want {
+			t.Errorf("%q: got class %v, want %v", script, err.Class, want)
+		}
+		if want != nil && !errors.Is(err, want) {
+			t.Errorf("%q: errors.Is(%v, %v) is false", script, err, want)
+		}
+		if !strings.HasPrefix(err.Error(), "eos fs ls -m: ") {
+			t.Errorf("%q: missing command line in %q", script, err.Error())
+		}
+	}
+}
+
+func TestCommandErrorTimeout(t *testing.T) {
+	err := commandError([]string{"inspector", "-m"}, context.DeadlineExceeded, strings.Repeat("x", 2*maxStderrLen), false)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if err.ExitCode != -1 {
+		t.Fatalf("expected exit code -1, got %d", err.ExitCode)
+	}
+	if len(err.Stderr) > maxStderrLen+3 {
+		t.Fatalf("stderr not truncated: %d bytes", len(err.Stderr))
+	}
+}
+
+func TestExecuteReturnsCommandError(t *testing.T) {
+	client, err := New(&Options{Executor: &fixtureExecutor{}})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	_, err = client.ListFS(context.Background(), "root")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected a *CommandError, got %T: %v", err, err)
+	}
+	if cmdErr.Command != "eos -r 0 0 fs ls -m" || cmdErr.Stderr != "unknown command" {
+		t.Fatalf("unexpected error %+v", cmdErr)
+	}
+}
+
+func TestParseErrorCommandLine(t *testing.T) {
+	client, err := New(&Options{URL: "root://mgm", Executor: &staticExecutor{stdout: "{not json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ListIOShapingConfig(context.Background())
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !errors.Is(err, ErrParse) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	if want := "eos root://mgm io shaping config ls --json"; cmdErr.Command != want {
+		t.Fatalf("expected the command line %q, got %q", want, cmdErr.Command)
+	}
+}
@@ -0,0 +1,33 @@
+package eosclient
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+)
+
+// Executor runs an eos command line and returns its stdout and stderr.
+// The arguments do not include the eos binary itself, e.g. "fs", "ls", "-m".
+type Executor interface {
+	Execute(ctx context.Context, args ...string) (stdout string, stderr string, err error)
+}
+
+// CommandExecutor runs the commands by spawning the eos CLI.
+type CommandExecutor struct {
+	// Location of the eos binary
+	Binary string
+}
+
+var _ Executor = &CommandExecutor{}
+
+// Execute spawns the eos binary with the given arguments and waits for it to finish.
+// The process is killed if the context is done before it exits.
+func (e *CommandExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	outBuf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, e.Binary, args...)
+	cmd.Stdout = outBuf
+	cmd.Stderr = errBuf
+	err := cmd.Run()
+	return outBuf.String(), errBuf.String(), err
+}
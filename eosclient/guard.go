@@ -0,0 +1,156 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrCircuitOpen is returned instead of running a command whose circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// GuardOptions configures a GuardedExecutor
+type GuardOptions struct {
+	// Maximum number of commands running concurrently. Defaults to no limit.
+	MaxConcurrent int
+
+	// Number of consecutive failures or timeouts of a command opening its
+	// circuit. Defaults to 0, never opening the circuits.
+	Threshold int
+
+	// Duration a command is not run once its circuit is open
+	Backoff time.Duration
+}
+
+// GuardedExecutor keeps the exporter from making an incident of the MGM worse.
+// It caps the number of commands running concurrently through another Executor,
+// and it has a circuit breaker per command: once a command failed Threshold times
+// in a row, it fails right away with ErrCircuitOpen for Backoff. A single call is
+// then let through, closing the circuit if it succeeds.
+type GuardedExecutor struct {
+	next Executor
+	opts GuardOptions
+	sem  chan struct{} // nil without limit
+
+	mu       sync.Mutex
+	circuits map[string]*circuit // by command name
+
+	openDesc *prometheus.Desc
+}
+
+type circuit struct {
+	failures  int       // consecutive failures
+	openUntil time.Time // the command is not run before, once failures reached the threshold
+}
+
+var _ Executor = &GuardedExecutor{}
+
+// NewGuardedExecutor returns an executor running the commands through next as allowed by opts
+func NewGuardedExecutor(next Executor, opts GuardOptions) *GuardedExecutor {
+	e := &GuardedExecutor{
+		next:     next,
+		opts:     opts,
+		circuits: make(map[string]*circuit),
+		openDesc: prometheus.NewDesc(
+			"eos_command_circuit_open",
+			"Whether the eos command is not run because it failed repeatedly",
+			[]string{"command"},
+			nil,
+		),
+	}
+	if opts.MaxConcurrent > 0 {
+		e.sem = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return e
+}
+
+func (e *GuardedExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	command := commandName(args)
+	if err := e.allow(command); err != nil {
+		return "", "", err
+	}
+
+	if e.sem != nil {
+		select {
+		case e.sem <- struct{}{}:
+			defer func() { <-e.sem }()
+		case <-ctx.Done():
+			return "", "", ctx.Err()
+		}
+	}
+
+	stdout, stderr, err := e.next.Execute(ctx, args...)
+	// A command abandoned by its caller says nothing about the MGM
+	if !errors.Is(context.Cause(ctx), context.Canceled) {
+		e.record(command, err)
+	}
+	return stdout, stderr, err
+}
+
+// allow returns ErrCircuitOpen if the circuit of the command is open. Once the
+// back-off is over, it lets one call through and keeps the circuit open for the others.
+func (e *GuardedExecutor) allow(command string) error {
+	if e.opts.Threshold <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.circuits[command]
+	if !ok || c.failures < e.opts.Threshold {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(c.openUntil) {
+		return fmt.Errorf("%w: not running eos %s until %s after %d consecutive failures", ErrCircuitOpen, command, c.openUntil.Format(time.RFC3339), c.failures)
+	}
+	c.openUntil = now.Add(e.opts.Backoff)
+	return nil
+}
+
+// record updates the circuit of the command with the outcome of a call
+func (e *GuardedExecutor) record(command string, err error) {
+	if e.opts.Threshold <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.circuits[command]
+	if !ok {
+		c = &circuit{}
+		e.circuits[command] = c
+	}
+	if err == nil {
+		c.failures = 0
+		c.openUntil = time.Time{}
+		return
+	}
+	c.failures++
+	if c.failures == e.opts.Threshold {
+		c.openUntil = time.Now().Add(e.opts.Backoff)
+	}
+}
+
+// Describe sends the descriptor of the circuit metric
+func (e *GuardedExecutor) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.openDesc
+}
+
+// Collect sends the state of the circuit of every command run so far
+func (e *GuardedExecutor) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	for command, c := range e.circuits {
+		var open float64
+		if c.failures >= e.opts.Threshold && now.Before(c.openUntil) {
+			open = 1
+		}
+		ch <- prometheus.MustNewConstMetric(e.openDesc, prometheus.GaugeValue, open, command)
+	}
+}
@@ -0,0 +1,107 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGuardOpensCircuit(t *testing.T) {
+	executor := &fixtureExecutor{outputs: map[string]string{"-r 0 0 fs ls -m": ""}}
+	guard := NewGuardedExecutor(executor, GuardOptions{Threshold: 2, Backoff: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := guard.Execute(ctx, "-r", "0", "0", "ns", "stat", "-m"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected the command to run and fail, got %v", i, err)
+		}
+	}
+	if _, _, err := guard.Execute(ctx, "-r", "0", "0", "ns", "stat", "-m"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if len(executor.calls) != 2 {
+		t.Fatalf("expected 2 commands to run, got %d", len(executor.calls))
+	}
+
+	// The circuits are per command
+	if _, _, err := guard.Execute(ctx, "-r", "0", "0", "fs", "ls", "-m"); err != nil {
+		t.Fatalf("fs ls returned error: %v", err)
+	}
+}
+
+func TestGuardClosesCircuitAfterBackoff(t *testing.T) {
+	executor := &fixtureExecutor{outputs: map[string]string{}}
+	guard := NewGuardedExecutor(executor, GuardOptions{Threshold: 1, Backoff: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	if _, _, err := guard.Execute(ctx, "version"); err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	if _, _, err := guard.Execute(ctx, "version"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	executor.outputs["version"] = "EOS_SERVER_VERSION=5.2.0\n"
+	if _, _, err := guard.Execute(ctx, "version"); err != nil {
+		t.Fatalf("expected the command to run after the back-off, got %v", err)
+	}
+	if _, _, err := guard.Execute(ctx, "version"); err != nil {
+		t.Fatalf("expected the circuit to be closed, got %v", err)
+	}
+}
+
+// gatedExecutor holds every command until gate is closed, tracking how many run at once
+type gatedExecutor struct {
+	gate    chan struct{}
+	started chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *gatedExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	n := e.running.Add(1)
+	defer e.running.Add(-1)
+	for peak := e.peak.Load(); n > peak && !e.peak.CompareAndSwap(peak, n); peak = e.peak.Load() {
+	}
+	e.started <- struct{}{}
+	<-e.gate
+	return "", "", nil
+}
+
+func TestGuardCapsConcurrentCommands(t *testing.T) {
+	executor := &gatedExecutor{gate: make(chan struct{}), started: make(chan struct{}, 5)}
+	guard := NewGuardedExecutor(executor, GuardOptions{MaxConcurrent: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := guard.Execute(context.Background(), "version"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	<-executor.started
+	<-executor.started
+
+	// Both slots are taken, a caller giving up while waiting does not run its command
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := guard.Execute(ctx, "version"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+
+	close(executor.gate)
+	wg.Wait()
+	if got := executor.peak.Load(); got != 2 {
+		t.Fatalf("expected at most 2 commands at once, got %d", got)
+	}
+	if got := len(executor.started); got != 3 {
+		t.Fatalf("expected the 3 waiting commands to run once slots were free, got %d", got)
+	}
+}
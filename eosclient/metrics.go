@@ -0,0 +1,121 @@
+package eosclient
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CommandMetrics are the duration and the failures of the eos commands run by the
+// clients using them. The clients of different instances get their own, so that
+// each instance reports its commands under its own labels.
+type CommandMetrics struct {
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+	keys     *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = &CommandMetrics{}
+
+// NewCommandMetrics returns the metrics to set in Options.Metrics
+func NewCommandMetrics() *CommandMetrics {
+	return &CommandMetrics{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "eos_command_duration_seconds",
+				Help:    "Duration of the eos commands run by the exporter",
+				Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"command"},
+		),
+		failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "eos_command_failures_total",
+				Help: "Number of eos commands that failed, by exit code (-1 when the command did not exit, e.g. it timed out) and class of error",
+			},
+			[]string{"command", "exit_code", "class"},
+		),
+		keys: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "eos_output_keys_total",
+				Help: "Number of lines of eos monitoring format output with a key unknown to the exporter, missing or with an invalid value, by type of output",
+			},
+			[]string{"type", "key", "problem"},
+		),
+	}
+}
+
+// defaultMetrics are the metrics of the clients created without Options.Metrics
+var defaultMetrics = NewCommandMetrics()
+
+// Metrics returns the collectors of the eos command metrics shared by the clients
+// created without Options.Metrics
+func Metrics() []prometheus.Collector {
+	return []prometheus.Collector{defaultMetrics}
+}
+
+// Describe sends the descriptors of the command metrics
+func (m *CommandMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.failures.Describe(ch)
+	m.keys.Describe(ch)
+}
+
+// Collect sends the command metrics to the provided prometheus channel
+func (m *CommandMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.failures.Collect(ch)
+	m.keys.Collect(ch)
+}
+
+// commandName returns the eos subcommand of args, e.g. "fs ls" for
+// "-r 0 0 fs ls -m": the words before the first flag, without the role
+// and the instance URL.
+func commandName(args []string) string {
+	if len(args) >= 3 && args[0] == "-r" {
+		args = args[3:]
+	}
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		if strings.Contains(arg, "://") {
+			continue
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// observeCommand records the duration and the outcome of an eos command
+func (m *CommandMetrics) observeCommand(args []string, start time.Time, err error) {
+	command := commandName(args)
+	m.duration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.observeFailure(command, err)
+	}
+}
+
+// observeFailure counts a failure of an eos command
+func (m *CommandMetrics) observeFailure(command string, err error) {
+	exitCode := -1
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		exitCode = cmdErr.ExitCode
+	}
+	m.failures.WithLabelValues(command, strconv.Itoa(exitCode), className(err)).Inc()
+}
+
+// observeDecode counts the keys of a decoded line of monitoring format output which
+// do not match the struct of type typ, which usually come from an EOS upgrade
+func (m *CommandMetrics) observeDecode(typ string, report decodeReport) {
+	for problem, keys := range map[string][]string{"unknown": report.Unknown, "missing": report.Missing, "invalid": report.Invalid} {
+		for _, key := range keys {
+			m.keys.WithLabelValues(typ, key, problem).Inc()
+		}
+	}
+}
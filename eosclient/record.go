@@ -0,0 +1,136 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Fixture files written for every command: <name>.stdout, <name>.stderr and,
+// when the command failed, <name>.err holding the error message.
+const (
+	fixtureStdoutExt = ".stdout"
+	fixtureStderrExt = ".stderr"
+	fixtureErrExt    = ".err"
+)
+
+// fixtureName maps a command line to the base file name of its fixture,
+// e.g. "-r 0 0 fs ls -m" becomes "-r_0_0_fs_ls_-m".
+func fixtureName(args []string) string {
+	name := strings.Join(args, "_")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-' || r == '.' || r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// RecordingExecutor runs the commands through another Executor and saves their
+// raw stdout, stderr and error into a fixture directory. The latest run of each
+// command overwrites the previous one.
+type RecordingExecutor struct {
+	next Executor
+	dir  string
+}
+
+var _ Executor = &RecordingExecutor{}
+
+// NewRecordingExecutor creates a timestamped fixture directory under baseDir and
+// returns an executor recording every command run through next into it.
+func NewRecordingExecutor(next Executor, baseDir string) (*RecordingExecutor, error) {
+	dir := filepath.Join(baseDir, time.Now().Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating record directory: %w", err)
+	}
+	return &RecordingExecutor{next: next, dir: dir}, nil
+}
+
+// Dir returns the fixture directory the commands are recorded into
+func (e *RecordingExecutor) Dir() string {
+	return e.dir
+}
+
+func (e *RecordingExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	stdout, stderr, err := e.next.Execute(ctx, args...)
+
+	base := filepath.Join(e.dir, fixtureName(args))
+	if werr := writeFileAtomic(base+fixtureStdoutExt, stdout); werr != nil {
+		return stdout, stderr, errors.Join(err, werr)
+	}
+	if werr := writeFileAtomic(base+fixtureStderrExt, stderr); werr != nil {
+		return stdout, stderr, errors.Join(err, werr)
+	}
+	if err != nil {
+		if werr := writeFileAtomic(base+fixtureErrExt, err.Error()); werr != nil {
+			return stdout, stderr, errors.Join(err, werr)
+		}
+	} else if rerr := os.Remove(base + fixtureErrExt); rerr != nil && !os.IsNotExist(rerr) {
+		return stdout, stderr, rerr
+	}
+
+	return stdout, stderr, err
+}
+
+// writeFileAtomic writes the content to a temporary file and renames it into
+// place, so that concurrent runs of the same command never leave a torn file.
+func writeFileAtomic(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReplayExecutor serves the outputs previously saved by a RecordingExecutor
+// instead of running the eos CLI.
+type ReplayExecutor struct {
+	// Fixture directory, e.g. <record-dir>/20260317-101500
+	Dir string
+}
+
+var _ Executor = &ReplayExecutor{}
+
+func (e *ReplayExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	base := filepath.Join(e.Dir, fixtureName(args))
+
+	stdout, err := os.ReadFile(base + fixtureStdoutExt)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("no recorded output for %q in %s", strings.Join(args, " "), e.Dir)
+		}
+		return "", "", err
+	}
+
+	stderr, err := os.ReadFile(base + fixtureStderrExt)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+
+	msg, err := os.ReadFile(base + fixtureErrExt)
+	if err == nil {
+		return string(stdout), string(stderr), errors.New(string(msg))
+	}
+	if !os.IsNotExist(err) {
+		return "", "", err
+	}
+
+	return string(stdout), string(stderr), nil
+}
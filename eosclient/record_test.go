@@ -0,0 +1,76 @@
+package eosclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type staticExecutor struct {
+	stdout string
+	stderr string
+	err    error
+}
+
+func (e *staticExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	return e.stdout, e.stderr, e.err
+}
+
+func TestFixtureName(t *testing.T) {
+	got := fixtureName([]string{"-r", "0", "0", "io", "shaping", "ls", "--window", "60", "--apps"})
+	want := "-r_0_0_io_shaping_ls_--window_60_--apps"
+	if got != want {
+		t.Fatalf("fixtureName = %q, want %q", got, want)
+	}
+
+	if got := fixtureName([]string{"ls", "/eos/user/../x"}); got != "ls__eos_user_.._x" {
+		t.Fatalf("fixtureName did not sanitize path separators: %q", got)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	args := []string{"-r", "0", "0", "node", "ls", "-m"}
+
+	recorder, err := NewRecordingExecutor(&staticExecutor{stdout: "hostport=fst-1:1095 status=online\n", stderr: "warning"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRecordingExecutor returned error: %v", err)
+	}
+	if _, _, err := recorder.Execute(ctx, args...); err != nil {
+		t.Fatalf("recording returned error: %v", err)
+	}
+
+	replay := &ReplayExecutor{Dir: recorder.Dir()}
+	stdout, stderr, err := replay.Execute(ctx, args...)
+	if err != nil {
+		t.Fatalf("replay returned error: %v", err)
+	}
+	if stdout != "hostport=fst-1:1095 status=online\n" || stderr != "warning" {
+		t.Fatalf("unexpected replayed output %q / %q", stdout, stderr)
+	}
+
+	if _, _, err := replay.Execute(ctx, "fs", "ls", "-m"); err == nil {
+		t.Fatal("expected an error replaying a command that was never recorded")
+	}
+}
+
+func TestRecordAndReplayFailure(t *testing.T) {
+	ctx := context.Background()
+
+	recorder, err := NewRecordingExecutor(&staticExecutor{stderr: "connection refused", err: errors.New("exit status 111")}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRecordingExecutor returned error: %v", err)
+	}
+	if _, _, err := recorder.Execute(ctx, "ns", "stat", "-m"); err == nil {
+		t.Fatal("expected the recorder to pass the error through")
+	}
+
+	replay := &ReplayExecutor{Dir: recorder.Dir()}
+	_, stderr, err := replay.Execute(ctx, "ns", "stat", "-m")
+	if err == nil || err.Error() != "exit status 111" {
+		t.Fatalf("expected replayed error exit status 111, got %v", err)
+	}
+	if stderr != "connection refused" {
+		t.Fatalf("expected replayed stderr, got %q", stderr)
+	}
+}
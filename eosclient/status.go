@@ -0,0 +1,56 @@
+package eosclient
+
+// The numeric fields of NodeInfo, GroupInfo, FSInfo, SpaceInfo, NSInfo and
+// RecycleInfo are pointers, nil when the value is missing from the eos output
+// or could not be parsed, the latter being reported in their Err. The status
+// fields below hold the value printed by eos, which may be a value unknown to
+// this package.
+
+// BootStatus is the boot status of a filesystem (stat.boot)
+type BootStatus string
+
+const (
+	BootBooted   BootStatus = "booted"
+	BootBooting  BootStatus = "booting"
+	BootFailure  BootStatus = "bootfailure"
+	BootOpsError BootStatus = "opserror"
+	BootDown     BootStatus = "down"
+)
+
+// ConfigStatus is the configuration status of a filesystem (configstatus)
+type ConfigStatus string
+
+const (
+	ConfigRW    ConfigStatus = "rw"
+	ConfigRO    ConfigStatus = "ro"
+	ConfigDrain ConfigStatus = "drain"
+	ConfigEmpty ConfigStatus = "empty"
+)
+
+// DrainStatus is the drain status of a filesystem (drainstatus)
+type DrainStatus string
+
+const (
+	DrainNone     DrainStatus = "nodrain"
+	DrainDrained  DrainStatus = "drained"
+	DrainDraining DrainStatus = "draining"
+	DrainStalling DrainStatus = "stalling"
+	DrainExpired  DrainStatus = "expired"
+)
+
+// ActiveStatus tells whether a node (status) or a filesystem (stat.active) is online
+type ActiveStatus string
+
+const (
+	Online  ActiveStatus = "online"
+	Offline ActiveStatus = "offline"
+)
+
+// BalancingStatus is the balancing status of a group (cfg.stat.balancing)
+type BalancingStatus string
+
+const (
+	BalancingIdle      BalancingStatus = "idle"
+	BalancingBalancing BalancingStatus = "balancing"
+	BalancingDrainWait BalancingStatus = "drainwait"
+)
@@ -0,0 +1,65 @@
+package eosclient
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandRun is the raw outcome of a run of an eos command
+type CommandRun struct {
+	Command  string // command line, e.g. "eos -r 0 0 fs ls -m"
+	Time     time.Time
+	Duration time.Duration
+	ExitCode int // -1 when the command did not exit, e.g. it was killed
+	Stdout   string
+	Stderr   string
+	Err      error
+}
+
+// CommandTrace keeps the last run of each command line run by the clients
+// using it, e.g. to compare a wrong metric with the output it was built from
+type CommandTrace struct {
+	mu   sync.Mutex
+	runs map[string]CommandRun
+}
+
+// NewCommandTrace returns an empty trace, to set in Options.Trace
+func NewCommandTrace() *CommandTrace {
+	return &CommandTrace{runs: make(map[string]CommandRun)}
+}
+
+// observe records a run of a command, replacing the previous run of the same command line
+func (t *CommandTrace) observe(args []string, start time.Time, stdout, stderr string, err error) {
+	run := CommandRun{
+		Command:  "eos " + strings.Join(args, " "),
+		Time:     start,
+		Duration: time.Since(start),
+		Stdout:   stdout,
+		Stderr:   stderr,
+		Err:      err,
+	}
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		run.ExitCode = cmdErr.ExitCode
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runs[run.Command] = run
+}
+
+// Runs returns the last run of each command line, sorted by command line
+func (t *CommandTrace) Runs() []CommandRun {
+	t.mu.Lock()
+	runs := make([]CommandRun, 0, len(t.runs))
+	for _, run := range t.runs {
+		runs = append(runs, run)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Command < runs[j].Command })
+	return runs
+}
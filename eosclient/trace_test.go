@@ -0,0 +1,34 @@
+package eosclient
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestTraceKeepsLastRun(t *testing.T) {
+	ctx := context.Background()
+	trace := NewCommandTrace()
+	executor := &staticExecutor{stdout: "first"}
+	client, err := New(&Options{URL: "root://mgm", Executor: executor, Trace: trace})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.execute(ctx, "fs", "ls", "-m")
+	executor.stdout = "second"
+	client.execute(ctx, "fs", "ls", "-m")
+	executor.stdout, executor.stderr, executor.err = "", "denied", exec.Command("sh", "-c", "exit 13").Run()
+	client.execute(ctx, "-r", "0", "0", "node", "ls", "-m")
+
+	runs := trace.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected the last run of 2 commands, got %d", len(runs))
+	}
+	if runs[0].Command != "eos -r 0 0 root://mgm node ls -m" || runs[0].ExitCode != 13 || runs[0].Stderr != "denied" || runs[0].Err == nil {
+		t.Fatalf("unexpected failed run %+v", runs[0])
+	}
+	if runs[1].Command != "eos root://mgm fs ls -m" || runs[1].Stdout != "second" || runs[1].ExitCode != 0 {
+		t.Fatalf("unexpected run %+v", runs[1])
+	}
+}
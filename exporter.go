@@ -0,0 +1,767 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+var (
+	errCollectorTimeout = errors.New("collector timed out")
+	errCollectorBusy    = errors.New("previous run of the collector is still in progress")
+)
+
+// stalePolicy is how long the metrics of the last successful run of a collector
+// are served after it starts failing
+type stalePolicy time.Duration
+
+const (
+	staleDrop    stalePolicy = 0  // serve nothing once the collector fails
+	staleForever stalePolicy = -1 // serve the last successful metrics until the collector recovers
+)
+
+// collectorSettings are the per-collector settings of an EOSExporter
+type collectorSettings struct {
+	Timeout  time.Duration // deadline of each run
+	Interval time.Duration // background refresh interval
+	Stale    stalePolicy
+	Filter   *labelFilter // series to keep, all of them if nil
+}
+
+// collectorSpec describes one collector an EOSExporter should run
+type collectorSpec struct {
+	name     string
+	creator  func(*collector.CollectorOpts) collector.Collector
+	opts     collector.CollectorOpts
+	settings collectorSettings
+}
+
+// managedCollector wraps one of the availableCollectors with the state the
+// exporter keeps about it between scrapes
+type managedCollector struct {
+	name      string
+	collector collector.Collector
+	opts      collector.CollectorOpts // options the collector was created with
+
+	// Held by the collection running the collector, so that the collections of
+	// overlapping selections of collectors run one after the other
+	collecting sync.Mutex
+
+	// Slots shared by the collectors of an exporter, bounding how many of them run at
+	// once. A collector holds one until it returned, even after its timeout.
+	// Unbounded if nil.
+	slots chan struct{}
+
+	mu          sync.Mutex
+	settings    collectorSettings
+	running     bool
+	metrics     []prometheus.Metric // metrics of the last successful run
+	lastSuccess time.Time
+	lastRun     time.Time
+	lastErr     error
+	duration    time.Duration // duration of the last run
+	series      int           // number of series of the last run, 0 when it failed
+}
+
+type updateResult struct {
+	metrics []prometheus.Metric
+	err     error
+}
+
+// run calls the collector and buffers the metrics it sends which pass the filter
+func (m *managedCollector) run(ctx context.Context, filter *labelFilter) updateResult {
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	var metrics []prometheus.Metric
+	go func() {
+		for metric := range ch {
+			if filter.keep(metric) {
+				metrics = append(metrics, metric)
+			}
+		}
+		close(done)
+	}()
+
+	err := m.collector.Update(ctx, ch)
+	close(ch)
+	<-done
+
+	return updateResult{metrics: metrics, err: err}
+}
+
+// fail records a run of the collector which did not start
+func (m *managedCollector) fail(err error) {
+	m.lastRun = time.Now()
+	m.duration = 0
+	m.series = 0
+	m.lastErr = err
+}
+
+// update runs the collector with ctx and keeps its metrics if it succeeded. A collector
+// not finishing within its timeout, or before ctx is done, is reported as failed: its
+// eos commands are killed and its metrics are discarded. It is not started again until
+// it has returned.
+func (m *managedCollector) update(ctx context.Context) ([]prometheus.Metric, error) {
+	m.mu.Lock()
+	if m.running {
+		m.fail(errCollectorBusy)
+		m.mu.Unlock()
+		return nil, errCollectorBusy
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	if m.slots != nil {
+		select {
+		case m.slots <- struct{}{}:
+		case <-ctx.Done():
+			err := fmt.Errorf("scrape abandoned waiting for a worker: %w", context.Cause(ctx))
+			m.mu.Lock()
+			m.running = false
+			m.fail(err)
+			m.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if settings.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, settings.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	start := time.Now()
+	results := make(chan updateResult, 1)
+	go func() {
+		res := m.run(runCtx, settings.Filter)
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+		if m.slots != nil {
+			<-m.slots
+		}
+		results <- res
+	}()
+
+	var res updateResult
+	select {
+	case res = <-results:
+	case <-runCtx.Done():
+		if ctx.Err() != nil {
+			res = updateResult{err: fmt.Errorf("scrape abandoned: %w", context.Cause(ctx))}
+		} else {
+			res = updateResult{err: fmt.Errorf("%w after %s", errCollectorTimeout, settings.Timeout)}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastRun = start
+	m.duration = time.Since(start)
+	m.lastErr = res.err
+	if res.err != nil {
+		m.series = 0
+		return nil, res.err
+	}
+	m.series = len(res.metrics)
+	m.metrics = res.metrics
+	m.lastSuccess = time.Now()
+	return res.metrics, nil
+}
+
+// latest returns the metrics of the last successful run and when it finished
+func (m *managedCollector) latest() ([]prometheus.Metric, time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.metrics, m.lastSuccess
+}
+
+// current returns the metrics to serve according to the stale policy.
+// ok is false when there is nothing to serve.
+func (m *managedCollector) current(now time.Time) (metrics []prometheus.Metric, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lastSuccess.IsZero() {
+		return nil, false
+	}
+	if m.lastErr != nil {
+		switch {
+		case m.settings.Stale == staleDrop:
+			return nil, false
+		case m.settings.Stale != staleForever && now.Sub(m.lastSuccess) > time.Duration(m.settings.Stale):
+			return nil, false
+		}
+	}
+	return m.metrics, true
+}
+
+// status returns whether the last run succeeded and how long it took.
+// ran is false as long as the collector never ran.
+func (m *managedCollector) status() (ran bool, success bool, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.lastRun.IsZero(), m.lastErr == nil, m.duration
+}
+
+// collectorReport describes the last run of a collector for the status page
+type collectorReport struct {
+	LastRun     time.Time
+	LastSuccess time.Time
+	Duration    time.Duration
+	Err         error
+	Series      int
+}
+
+func (m *managedCollector) report() collectorReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return collectorReport{
+		LastRun:     m.lastRun,
+		LastSuccess: m.lastSuccess,
+		Duration:    m.duration,
+		Err:         m.lastErr,
+		Series:      m.series,
+	}
+}
+
+// pollGroup refreshes in the background all the collectors sharing the same interval.
+// The collectors of a group share a snapshot, so a command used by several of them
+// (e.g. eos inspector -m) still runs once per refresh.
+type pollGroup struct {
+	interval   time.Duration
+	snapshot   *collector.Snapshot
+	collectors []*managedCollector
+}
+
+// exporterOpts controls how an EOSExporter runs its collectors
+type exporterOpts struct {
+	Background bool // refresh the collectors in the background instead of during the scrapes
+	Workers    int  // maximum number of collectors running concurrently
+
+	// Scrapes arriving within Coalesce after a collection finished are served its
+	// results. Scrapes arriving while it runs always are.
+	Coalesce time.Duration
+}
+
+// collection is a run of some collectors of an EOSExporter in scrape mode, shared by
+// the concurrent scrapes of the same collectors so that they get identical results
+type collection struct {
+	done     chan struct{}
+	cancel   context.CancelCauseFunc
+	waiting  int                 // scrapes waiting for the collection, canceled when they all left
+	metrics  []prometheus.Metric // set once done
+	finished time.Time
+
+	// The collection is canceled at the latest deadline of the scrapes waiting for
+	// it, extended as they join. It has none as soon as one of them has none.
+	deadline time.Time
+	timer    *time.Timer // nil without deadline
+}
+
+// extend pushes the deadline of the collection to the one of ctx if it is later
+func (col *collection) extend(ctx context.Context) {
+	if col.timer == nil {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		col.timer.Stop()
+		col.timer = nil
+		return
+	}
+	if deadline.After(col.deadline) {
+		col.deadline = deadline
+		col.timer.Reset(time.Until(deadline))
+	}
+}
+
+// EOSExporter wraps a list of registered EOS collectors.
+// By default the collectors run concurrently during each scrape. In background
+// mode they refresh on their own interval and scrapes serve the latest metrics.
+type EOSExporter struct {
+	mu         sync.RWMutex
+	opts       exporterOpts
+	snapshot   *collector.Snapshot // eos command outputs shared by the collectors during one scrape
+	collectors []*managedCollector
+	groups     map[time.Duration]*pollGroup
+	slots      chan struct{} // one per collector allowed to run at once
+
+	// Snapshots of the poll groups by interval, and clients running their commands
+	// through the snapshots. They outlive the groups so that the collectors kept by
+	// apply still share their commands with their group.
+	snapshots map[time.Duration]*collector.Snapshot
+	clients   map[clientKey]*eosclient.Client
+
+	lastSuccessDesc *prometheus.Desc
+	dataAgeDesc     *prometheus.Desc
+	successDesc     *prometheus.Desc
+	durationDesc    *prometheus.Desc
+	coalescedDesc   *prometheus.Desc
+
+	collectionsMu sync.Mutex
+	collections   map[string]*collection // latest collection of each selection of collectors
+	coalesced     atomic.Uint64
+
+	cancel context.CancelFunc // stops the background refreshes
+	wg     sync.WaitGroup
+}
+
+var _ collector.ContextCollector = &EOSExporter{}
+
+func newEOSExporter(cluster string, snapshot *collector.Snapshot, opts exporterOpts) *EOSExporter {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	return &EOSExporter{
+		opts:      opts,
+		snapshot:  snapshot,
+		slots:     make(chan struct{}, opts.Workers),
+		groups:    make(map[time.Duration]*pollGroup),
+		snapshots: make(map[time.Duration]*collector.Snapshot),
+		clients:   make(map[clientKey]*eosclient.Client),
+
+		collections: make(map[string]*collection),
+		lastSuccessDesc: prometheus.NewDesc(
+			"eos_collector_last_success_timestamp_seconds",
+			"Unix timestamp of the last successful run of the collector",
+			[]string{"collector"},
+			prometheus.Labels{"cluster": cluster},
+		),
+		dataAgeDesc: prometheus.NewDesc(
+			"eos_collector_data_age_seconds",
+			"Seconds since the last successful run of the collector, i.e. the age of its metrics when they are served from an earlier run",
+			[]string{"collector"},
+			prometheus.Labels{"cluster": cluster},
+		),
+		successDesc: prometheus.NewDesc(
+			"eos_scrape_collector_success",
+			"Whether the last run of the collector succeeded",
+			[]string{"collector"},
+			prometheus.Labels{"cluster": cluster},
+		),
+		durationDesc: prometheus.NewDesc(
+			"eos_scrape_collector_duration_seconds",
+			"Duration of the last run of the collector",
+			[]string{"collector"},
+			prometheus.Labels{"cluster": cluster},
+		),
+		coalescedDesc: prometheus.NewDesc(
+			"eos_scrapes_coalesced_total",
+			"Number of scrapes served the results of a collection started by another scrape",
+			nil,
+			prometheus.Labels{"cluster": cluster},
+		),
+	}
+}
+
+// apply replaces the collectors of the exporter by the specified ones. A collector
+// whose options did not change is kept with its state (e.g. the audit counters) and
+// only its settings are updated. The dropped collectors having a Stop method are stopped.
+// In background mode the refreshes are restarted with the new collectors.
+func (c *EOSExporter) apply(specs []collectorSpec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopGroups()
+
+	existing := make(map[string]*managedCollector, len(c.collectors))
+	for _, mc := range c.collectors {
+		existing[mc.name] = mc
+	}
+
+	collectors := make([]*managedCollector, 0, len(specs))
+	for _, spec := range specs {
+		snapshot := c.snapshot
+		if c.opts.Background {
+			var ok bool
+			if snapshot, ok = c.snapshots[spec.settings.Interval]; !ok {
+				snapshot = c.snapshot.Fork()
+				c.snapshots[spec.settings.Interval] = snapshot
+			}
+		}
+		opts := spec.opts
+		opts.Client = c.client(spec.opts.Client, snapshot)
+
+		mc, ok := existing[spec.name]
+		if ok && mc.opts == opts {
+			delete(existing, spec.name)
+			mc.mu.Lock()
+			if !mc.settings.Filter.equal(spec.settings.Filter) {
+				// The cached metrics went through the previous filter
+				mc.metrics = nil
+				mc.lastSuccess = time.Time{}
+			}
+			mc.settings = spec.settings
+			mc.mu.Unlock()
+		} else {
+			mc = &managedCollector{name: spec.name, opts: opts, settings: spec.settings, slots: c.slots}
+			mc.collector = spec.creator(&opts)
+		}
+		collectors = append(collectors, mc)
+
+		if c.opts.Background {
+			group, ok := c.groups[spec.settings.Interval]
+			if !ok {
+				group = &pollGroup{interval: spec.settings.Interval, snapshot: snapshot}
+				c.groups[spec.settings.Interval] = group
+			}
+			group.collectors = append(group.collectors, mc)
+		}
+	}
+	c.collectors = collectors
+
+	for _, mc := range existing {
+		if stopper, ok := mc.collector.(interface{ Stop() }); ok {
+			stopper.Stop()
+		}
+	}
+
+	c.startGroups()
+}
+
+type clientKey struct {
+	base     *eosclient.Client
+	snapshot *collector.Snapshot
+}
+
+// client returns the client running the commands of base through the snapshot
+func (c *EOSExporter) client(base *eosclient.Client, snapshot *collector.Snapshot) *eosclient.Client {
+	key := clientKey{base: base, snapshot: snapshot}
+	client, ok := c.clients[key]
+	if !ok {
+		client = base.WithExecutor(snapshot)
+		c.clients[key] = client
+	}
+	return client
+}
+
+// startGroups launches the background refresh of the poll groups
+func (c *EOSExporter) startGroups() {
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	for _, group := range c.groups {
+		c.wg.Add(1)
+		go func(g *pollGroup) {
+			defer c.wg.Done()
+
+			ticker := time.NewTicker(g.interval)
+			defer ticker.Stop()
+
+			for {
+				g.snapshot.Reset()
+				c.updateAll(ctx, g.collectors)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(group)
+	}
+}
+
+// stopGroups stops the background refreshes, killing their running eos commands,
+// and removes the poll groups
+func (c *EOSExporter) stopGroups() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.wg.Wait()
+	c.groups = make(map[time.Duration]*pollGroup)
+}
+
+// Stop stops the background refreshes
+func (c *EOSExporter) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopGroups()
+}
+
+// updateAll runs the collectors concurrently with ctx. At most opts.Workers collectors
+// of the exporter run at a time, counting the ones still running past their timeout.
+func (c *EOSExporter) updateAll(ctx context.Context, collectors []*managedCollector) {
+	var wg sync.WaitGroup
+	for _, mc := range collectors {
+		wg.Add(1)
+		go func(mc *managedCollector) {
+			defer wg.Done()
+
+			if _, err := mc.update(ctx); err != nil {
+				log.Printf("failed collecting %s metrics: %v", mc.name, err)
+			}
+		}(mc)
+	}
+	wg.Wait()
+}
+
+func (c *EOSExporter) Describe(ch chan<- *prometheus.Desc) {
+	c.describe(ch, nil)
+}
+
+func (c *EOSExporter) Collect(ch chan<- prometheus.Metric) {
+	c.collect(context.Background(), ch, nil)
+}
+
+// CollectWithContext is Collect with the collectors bound to ctx, e.g. the one of the scrape
+func (c *EOSExporter) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.collect(ctx, ch, nil)
+}
+
+// reports returns the last run of each collector of the exporter, by name
+func (c *EOSExporter) reports() map[string]collectorReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	reports := make(map[string]collectorReport, len(c.collectors))
+	for _, mc := range c.collectors {
+		reports[mc.name] = mc.report()
+	}
+	return reports
+}
+
+// selection returns the collectors of the exporter for which keep returns true, all of them if keep is nil
+func (c *EOSExporter) selection(keep func(name string) bool) []*managedCollector {
+	if keep == nil {
+		return c.collectors
+	}
+	var collectors []*managedCollector
+	for _, mc := range c.collectors {
+		if keep(mc.name) {
+			collectors = append(collectors, mc)
+		}
+	}
+	return collectors
+}
+
+func (c *EOSExporter) describe(ch chan<- *prometheus.Desc, keep func(name string) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	c.snapshot.Describe(ch)
+	ch <- c.lastSuccessDesc
+	ch <- c.dataAgeDesc
+	ch <- c.successDesc
+	ch <- c.durationDesc
+	ch <- c.coalescedDesc
+	for _, mc := range c.selection(keep) {
+		mc.collector.Describe(ch)
+	}
+}
+
+func (c *EOSExporter) collect(ctx context.Context, ch chan<- prometheus.Metric, keep func(name string) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var metrics []prometheus.Metric
+	if c.opts.Background {
+		metrics = c.gather(c.selection(keep))
+	} else {
+		metrics = c.join(ctx, c.selection(keep))
+	}
+	for _, metric := range metrics {
+		ch <- metric
+	}
+	ch <- prometheus.MustNewConstMetric(c.coalescedDesc, prometheus.CounterValue, float64(c.coalesced.Load()))
+}
+
+// join returns the metrics of a collection of the collectors: the running one, or the
+// last one if it finished within the coalescing window, or else a new one. It returns
+// nil when ctx is done first.
+func (c *EOSExporter) join(ctx context.Context, collectors []*managedCollector) []prometheus.Metric {
+	names := make([]string, len(collectors))
+	for i, mc := range collectors {
+		names[i] = mc.name
+	}
+	key := strings.Join(names, ",")
+
+	c.collectionsMu.Lock()
+	now := time.Now()
+	for k, col := range c.collections {
+		if !col.finished.IsZero() && now.Sub(col.finished) > c.opts.Coalesce {
+			delete(c.collections, k)
+		}
+	}
+	col, ok := c.collections[key]
+	if ok {
+		c.coalesced.Add(1)
+		if col.finished.IsZero() {
+			col.extend(ctx)
+		}
+	} else {
+		col = c.start(ctx, key, collectors)
+		c.collections[key] = col
+	}
+	col.waiting++
+	c.collectionsMu.Unlock()
+
+	defer func() {
+		c.collectionsMu.Lock()
+		defer c.collectionsMu.Unlock()
+		col.waiting--
+		if col.waiting == 0 && col.finished.IsZero() {
+			// Nobody is left to serve, the next scrape starts over
+			col.cancel(context.Canceled)
+			if c.collections[key] == col {
+				delete(c.collections, key)
+			}
+		}
+	}()
+
+	select {
+	case <-col.done:
+		return col.metrics
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// start runs a collection of the collectors in the background. It is canceled at the
+// latest deadline of the scrapes waiting for it, or once they are all gone. Collections
+// of disjoint selections of collectors run concurrently, each one from fresh eos
+// command outputs; the ones sharing collectors wait for each other.
+func (c *EOSExporter) start(ctx context.Context, key string, collectors []*managedCollector) *collection {
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	col := &collection{done: make(chan struct{}), cancel: cancel}
+	if deadline, ok := ctx.Deadline(); ok {
+		col.deadline = deadline
+		col.timer = time.AfterFunc(time.Until(deadline), func() { cancel(context.DeadlineExceeded) })
+	}
+	runCtx = c.snapshot.WithScope(runCtx)
+
+	// Locked in the same order by every collection
+	locked := append([]*managedCollector(nil), collectors...)
+	sort.Slice(locked, func(i, j int) bool { return locked[i].name < locked[j].name })
+
+	go func() {
+		defer cancel(context.Canceled)
+
+		for _, mc := range locked {
+			mc.collecting.Lock()
+		}
+		// Abandoned while waiting for another collection, the collectors are not run
+		if runCtx.Err() == nil {
+			c.updateAll(runCtx, collectors)
+		}
+		metrics := c.gather(collectors)
+		for _, mc := range locked {
+			mc.collecting.Unlock()
+		}
+
+		c.collectionsMu.Lock()
+		if col.timer != nil {
+			col.timer.Stop()
+		}
+		col.metrics = metrics
+		col.finished = time.Now()
+		c.collectionsMu.Unlock()
+		close(col.done)
+	}()
+	return col
+}
+
+// gather returns the metrics to serve for the collectors, along with their status
+// and the metrics of the snapshot
+func (c *EOSExporter) gather(collectors []*managedCollector) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	now := time.Now()
+	for _, mc := range collectors {
+		// A failed collector serves the metrics of its last successful run as long as its stale policy allows it
+		if served, ok := mc.current(now); ok {
+			metrics = append(metrics, served...)
+		}
+
+		if ran, success, duration := mc.status(); ran {
+			var value float64
+			if success {
+				value = 1
+			}
+			metrics = append(metrics,
+				prometheus.MustNewConstMetric(c.successDesc, prometheus.GaugeValue, value, mc.name),
+				prometheus.MustNewConstMetric(c.durationDesc, prometheus.GaugeValue, duration.Seconds(), mc.name),
+			)
+		}
+		if _, lastSuccess := mc.latest(); !lastSuccess.IsZero() {
+			metrics = append(metrics,
+				prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, float64(lastSuccess.UnixNano())/1e9, mc.name),
+				prometheus.MustNewConstMetric(c.dataAgeDesc, prometheus.GaugeValue, now.Sub(lastSuccess).Seconds(), mc.name),
+			)
+		}
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.snapshot.Collect(ch)
+		close(ch)
+	}()
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// View returns a collector serving the collectors of the exporter with ctx: only
+// the ones named in collect, or all of them but the ones named in exclude. Naming
+// a collector the exporter does not run is an error.
+func (c *EOSExporter) View(ctx context.Context, collect, exclude []string) (prometheus.Collector, error) {
+	view := &exporterView{exporter: c, ctx: ctx}
+	if len(collect) == 0 && len(exclude) == 0 {
+		return view, nil
+	}
+	if len(collect) > 0 && len(exclude) > 0 {
+		return nil, errors.New("collect[] and exclude[] are mutually exclusive")
+	}
+
+	c.mu.RLock()
+	running := make(map[string]bool, len(c.collectors))
+	for _, mc := range c.collectors {
+		running[mc.name] = true
+	}
+	c.mu.RUnlock()
+
+	names := make(map[string]bool)
+	for _, name := range append(collect, exclude...) {
+		if !running[name] {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+		names[name] = true
+	}
+
+	view.keep = func(name string) bool { return names[name] }
+	if len(exclude) > 0 {
+		view.keep = func(name string) bool { return !names[name] }
+	}
+	return view, nil
+}
+
+// exporterView serves some of the collectors of an EOSExporter with a context
+type exporterView struct {
+	exporter *EOSExporter
+	ctx      context.Context
+	keep     func(name string) bool // all the collectors if nil
+}
+
+func (v *exporterView) Describe(ch chan<- *prometheus.Desc) {
+	v.exporter.describe(ch, v.keep)
+}
+
+func (v *exporterView) Collect(ch chan<- prometheus.Metric) {
+	v.exporter.collect(v.ctx, ch, v.keep)
+}
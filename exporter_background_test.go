@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackgroundPolling(t *testing.T) {
+	fake := newFakeCollector("a")
+	fake.set(3, nil)
+	// The first refresh runs right away, the next one in an hour
+	exporter := newTestExporter(t, exporterOpts{Background: true, Workers: 1}, collectorSettings{Interval: time.Hour}, map[string]*fakeCollector{"a": fake})
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if _, lastSuccess := exporter.collectors[0].latest(); !lastSuccess.IsZero() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the collector was not refreshed in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Scrapes serve the latest metrics without running the collector
+	for i := 0; i < 2; i++ {
+		if v := values(t, collectAll(exporter)); v["fake_a"] != 3 {
+			t.Fatalf("expected the metrics of the background refresh, got %v", v)
+		}
+	}
+	if got := fake.calls.Load(); got != 1 {
+		t.Fatalf("expected the scrapes not to run the collector, ran %d times", got)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectorTimeoutAndBusy(t *testing.T) {
+	fake := newFakeCollector("a")
+	fake.gate = make(chan struct{})
+	fake.ignoreCtx = true
+	mc := &managedCollector{name: "a", collector: fake, settings: collectorSettings{Timeout: 20 * time.Millisecond}}
+
+	if _, err := mc.update(context.Background()); !errors.Is(err, errCollectorTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	// The collector ignoring its context is still running, it is not started again
+	if _, err := mc.update(context.Background()); !errors.Is(err, errCollectorBusy) {
+		t.Fatalf("expected the collector to be busy, got %v", err)
+	}
+	if got := fake.calls.Load(); got != 1 {
+		t.Fatalf("expected a single run, got %d", got)
+	}
+	close(fake.gate)
+}
+
+func TestTimedOutCollectorKeepsItsWorker(t *testing.T) {
+	slots := make(chan struct{}, 1)
+	slow := newFakeCollector("slow")
+	slow.gate = make(chan struct{})
+	slow.ignoreCtx = true
+	fast := newFakeCollector("fast")
+	slowMC := &managedCollector{name: "slow", collector: slow, settings: collectorSettings{Timeout: 20 * time.Millisecond}, slots: slots}
+	fastMC := &managedCollector{name: "fast", collector: fast, slots: slots}
+
+	if _, err := slowMC.update(context.Background()); !errors.Is(err, errCollectorTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	// The timed out collector still runs, the next one waits until the scrape is gone
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := fastMC.update(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the scrape to end waiting for a worker, got %v", err)
+	}
+	if got := fast.calls.Load(); got != 0 {
+		t.Fatalf("expected the collector not to run, ran %d times", got)
+	}
+
+	// Its worker is free once the timed out collector returned
+	close(slow.gate)
+	if _, err := fastMC.update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fast.calls.Load(); got != 1 {
+		t.Fatalf("expected a single run, got %d", got)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+func TestStalePolicies(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name   string
+		policy stalePolicy
+		after  time.Duration // time since the last success when checked
+		served bool
+	}{
+		{"drop", staleDrop, 0, false},
+		{"within the duration", stalePolicy(time.Hour), 30 * time.Minute, true},
+		{"expired", stalePolicy(time.Hour), 2 * time.Hour, false},
+		{"forever", staleForever, 1000 * time.Hour, true},
+	} {
+		fake := newFakeCollector("a")
+		mc := &managedCollector{name: "a", collector: fake, settings: collectorSettings{Stale: tc.policy}}
+
+		fake.set(1, nil)
+		if _, err := mc.update(ctx); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		fake.set(2, errors.New("eos down"))
+		if _, err := mc.update(ctx); err == nil {
+			t.Fatalf("%s: expected the failure to be reported", tc.name)
+		}
+
+		metrics, ok := mc.current(time.Now().Add(tc.after))
+		if ok != tc.served {
+			t.Fatalf("%s: expected served=%v, got %v", tc.name, tc.served, ok)
+		}
+		if ok && values(t, metrics)["fake_a"] != 1 {
+			t.Fatalf("%s: expected the metrics of the last successful run, got %v", tc.name, values(t, metrics))
+		}
+		if ran, success, _ := mc.status(); !ran || success {
+			t.Fatalf("%s: expected the last run to be reported as failed", tc.name)
+		}
+	}
+}
+
+func TestFilterChangeDropsCachedMetrics(t *testing.T) {
+	client, err := eosclient.New(&eosclient.Options{Executor: nopExecutor{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeCollector("a")
+	exporter := newEOSExporter("test", collector.NewSnapshot(nopExecutor{}, "test"), exporterOpts{Workers: 1})
+	t.Cleanup(exporter.Stop)
+	apply := func(labels LabelsConfig) {
+		filter, err := newLabelFilter(labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exporter.apply([]collectorSpec{{
+			name:     "a",
+			creator:  func(*collector.CollectorOpts) collector.Collector { return fake },
+			opts:     collector.CollectorOpts{Cluster: "test", Client: client},
+			settings: collectorSettings{Stale: staleForever, Filter: filter},
+		}})
+	}
+
+	apply(LabelsConfig{Deny: map[string]string{"space": "spare"}})
+	fake.set(1, nil)
+	exporter.join(context.Background(), exporter.collectors)
+	fake.set(0, errors.New("eos down"))
+
+	// The same filter, reloaded, keeps the cached metrics
+	apply(LabelsConfig{Deny: map[string]string{"space": "spare"}})
+	if v := values(t, exporter.join(context.Background(), exporter.collectors)); v["fake_a"] != 1 {
+		t.Fatalf("expected the cached metrics, got %v", v)
+	}
+
+	apply(LabelsConfig{Deny: map[string]string{"space": "default"}})
+	if v := values(t, exporter.join(context.Background(), exporter.collectors)); len(exporter.collectors) != 1 || v["fake_a"] != 0 {
+		t.Fatalf("expected the cached metrics to be dropped with the filter change, got %v", v)
+	}
+}
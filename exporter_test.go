@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// fakeCollector sends a single gauge fake_<name> with its current value
+type fakeCollector struct {
+	desc  *prometheus.Desc
+	calls atomic.Int32
+
+	// When set, each run signals started and then waits for gate, or for its
+	// context unless ignoreCtx is set
+	started   chan struct{}
+	gate      chan struct{}
+	ignoreCtx bool
+
+	mu    sync.Mutex
+	value float64
+	err   error
+}
+
+func newFakeCollector(name string) *fakeCollector {
+	return &fakeCollector{desc: prometheus.NewDesc("fake_"+name, "fake", nil, nil)}
+}
+
+func (f *fakeCollector) set(value float64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.value, f.err = value, err
+}
+
+func (f *fakeCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	f.calls.Add(1)
+	if f.started != nil {
+		f.started <- struct{}{}
+	}
+	if f.gate != nil {
+		if f.ignoreCtx {
+			<-f.gate
+		} else {
+			select {
+			case <-f.gate:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	ch <- prometheus.MustNewConstMetric(f.desc, prometheus.GaugeValue, f.value)
+	return nil
+}
+
+func (f *fakeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- f.desc
+}
+
+func (f *fakeCollector) Collect(ch chan<- prometheus.Metric) {
+	f.CollectWithContext(context.Background(), ch)
+}
+
+func (f *fakeCollector) CollectWithContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	f.Update(ctx, ch)
+}
+
+// nopExecutor answers every eos command with an empty output
+type nopExecutor struct{}
+
+func (nopExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	return "", "", nil
+}
+
+// fakeSpecs returns the specs of the collectors, by name, with the same settings
+// and a client running no eos command
+func fakeSpecs(t *testing.T, settings collectorSettings, collectors map[string]*fakeCollector) []collectorSpec {
+	t.Helper()
+	client, err := eosclient.New(&eosclient.Options{Executor: nopExecutor{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var specs []collectorSpec
+	for name, fake := range collectors {
+		fake := fake
+		specs = append(specs, collectorSpec{
+			name:     name,
+			creator:  func(*collector.CollectorOpts) collector.Collector { return fake },
+			opts:     collector.CollectorOpts{Cluster: "test", Client: client},
+			settings: settings,
+		})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].name < specs[j].name })
+	return specs
+}
+
+// newTestExporter returns an exporter running the collectors, by name
+func newTestExporter(t *testing.T, opts exporterOpts, settings collectorSettings, collectors map[string]*fakeCollector) *EOSExporter {
+	t.Helper()
+	exporter := newEOSExporter("test", collector.NewSnapshot(nopExecutor{}, "test"), opts)
+	t.Cleanup(exporter.Stop)
+	exporter.apply(fakeSpecs(t, settings, collectors))
+	return exporter
+}
+
+var fqNameRe = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// values returns the values of the metrics by name, suffixed with the collector label if any
+func values(t *testing.T, metrics []prometheus.Metric) map[string]float64 {
+	t.Helper()
+	res := make(map[string]float64)
+	for _, m := range metrics {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		name := fqNameRe.FindStringSubmatch(m.Desc().String())[1]
+		for _, label := range pb.Label {
+			if label.GetName() == "collector" {
+				name += "/" + label.GetValue()
+			}
+		}
+		switch {
+		case pb.Gauge != nil:
+			res[name] = pb.Gauge.GetValue()
+		case pb.Counter != nil:
+			res[name] = pb.Counter.GetValue()
+		}
+	}
+	return res
+}
+
+// collectAll collects the metrics of a prometheus collector
+func collectAll(c prometheus.Collector) []prometheus.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestConcurrentScrapesShareACollection(t *testing.T) {
+	fake := newFakeCollector("a")
+	fake.started = make(chan struct{}, 1)
+	fake.gate = make(chan struct{})
+	fake.set(7, nil)
+	exporter := newTestExporter(t, exporterOpts{Workers: 1}, collectorSettings{}, map[string]*fakeCollector{"a": fake})
+
+	results := make(chan []prometheus.Metric, 2)
+	go func() { results <- exporter.join(context.Background(), exporter.collectors) }()
+	<-fake.started
+	go func() { results <- exporter.join(context.Background(), exporter.collectors) }()
+	// Wait for the second scrape to join the running collection
+	for deadline := time.Now().Add(5 * time.Second); exporter.coalesced.Load() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the second scrape did not join the collection")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(fake.gate)
+
+	for i := 0; i < 2; i++ {
+		if v := values(t, <-results); v["fake_a"] != 7 || v["eos_scrape_collector_success/a"] != 1 {
+			t.Fatalf("unexpected metrics %v", v)
+		}
+	}
+	if got := fake.calls.Load(); got != 1 {
+		t.Fatalf("expected the scrapes to share one run, got %d", got)
+	}
+}
+
+func TestCoalesceWindow(t *testing.T) {
+	for _, tc := range []struct {
+		window time.Duration
+		runs   int32
+	}{
+		{0, 2},
+		{time.Hour, 1},
+	} {
+		fake := newFakeCollector("a")
+		exporter := newTestExporter(t, exporterOpts{Workers: 1, Coalesce: tc.window}, collectorSettings{}, map[string]*fakeCollector{"a": fake})
+
+		exporter.join(context.Background(), exporter.collectors)
+		exporter.join(context.Background(), exporter.collectors)
+		if got := fake.calls.Load(); got != tc.runs {
+			t.Fatalf("coalesce window %s: expected %d runs, got %d", tc.window, tc.runs, got)
+		}
+	}
+}
+
+func TestAbandonedCollectionIsCanceled(t *testing.T) {
+	fake := newFakeCollector("a")
+	fake.started = make(chan struct{}, 2)
+	fake.gate = make(chan struct{})
+	exporter := newTestExporter(t, exporterOpts{Workers: 1}, collectorSettings{}, map[string]*fakeCollector{"a": fake})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []prometheus.Metric)
+	go func() { done <- exporter.join(ctx, exporter.collectors) }()
+	<-fake.started
+	cancel()
+	if metrics := <-done; metrics != nil {
+		t.Fatalf("expected nothing for an abandoned scrape, got %d metrics", len(metrics))
+	}
+
+	// The collection was canceled with its last waiter, the next scrape starts a new
+	// one once the collector returned
+	for deadline := time.Now().Add(5 * time.Second); len(exporter.slots) > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the collector was not canceled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() { done <- exporter.join(context.Background(), exporter.collectors) }()
+	<-fake.started
+	close(fake.gate)
+	if v := values(t, <-done); v["eos_scrape_collector_success/a"] != 1 {
+		t.Fatalf("expected the new collection to succeed, got %v", v)
+	}
+	if got := fake.calls.Load(); got != 2 {
+		t.Fatalf("expected a new run, got %d runs", got)
+	}
+}
+
+func TestDisjointCollectionsRunConcurrently(t *testing.T) {
+	a, b := newFakeCollector("a"), newFakeCollector("b")
+	a.started, b.started = make(chan struct{}, 1), make(chan struct{}, 1)
+	a.gate = make(chan struct{})
+	exporter := newTestExporter(t, exporterOpts{Workers: 2}, collectorSettings{}, map[string]*fakeCollector{"a": a, "b": b})
+	byName := make(map[string]*managedCollector)
+	for _, mc := range exporter.collectors {
+		byName[mc.name] = mc
+	}
+
+	done := make(chan []prometheus.Metric)
+	go func() { done <- exporter.join(context.Background(), []*managedCollector{byName["a"]}) }()
+	<-a.started
+	// a is still running, b is not held up by it
+	if v := values(t, exporter.join(context.Background(), []*managedCollector{byName["b"]})); v["eos_scrape_collector_success/b"] != 1 {
+		t.Fatalf("unexpected metrics %v", v)
+	}
+	<-b.started
+
+	// Overlapping selections wait for each other
+	go func() { done <- exporter.join(context.Background(), exporter.collectors) }()
+	select {
+	case <-b.started:
+		t.Fatal("b ran while a was still running in another collection")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(a.gate)
+	for i := 0; i < 2; i++ {
+		if v := values(t, <-done); v["eos_scrape_collector_success/a"] != 1 {
+			t.Fatalf("unexpected metrics %v", v)
+		}
+	}
+}
+
+func TestJoinerExtendsTheDeadline(t *testing.T) {
+	fake := newFakeCollector("a")
+	fake.started = make(chan struct{}, 1)
+	fake.gate = make(chan struct{})
+	exporter := newTestExporter(t, exporterOpts{Workers: 1}, collectorSettings{}, map[string]*fakeCollector{"a": fake})
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	first := make(chan []prometheus.Metric)
+	go func() { first <- exporter.join(short, exporter.collectors) }()
+	<-fake.started
+	second := make(chan []prometheus.Metric)
+	go func() { second <- exporter.join(context.Background(), exporter.collectors) }()
+	for deadline := time.Now().Add(5 * time.Second); exporter.coalesced.Load() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the second scrape did not join the collection")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The first scrape gives up, the collection goes on for the second one
+	if metrics := <-first; metrics != nil {
+		t.Fatalf("expected nothing for the timed out scrape, got %d metrics", len(metrics))
+	}
+	close(fake.gate)
+	if v := values(t, <-second); v["eos_scrape_collector_success/a"] != 1 {
+		t.Fatalf("expected the collection to outlive the first deadline, got %v", v)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestViewSelectsCollectors(t *testing.T) {
+	a, b := newFakeCollector("a"), newFakeCollector("b")
+	exporter := newTestExporter(t, exporterOpts{Workers: 2}, collectorSettings{}, map[string]*fakeCollector{"a": a, "b": b})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		collect, exclude []string
+		want, unwanted   string
+	}{
+		{[]string{"a"}, nil, "fake_a", "fake_b"},
+		{nil, []string{"a"}, "fake_b", "fake_a"},
+	} {
+		view, err := exporter.View(ctx, tc.collect, tc.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := values(t, collectAll(view))
+		if _, ok := v[tc.want]; !ok {
+			t.Fatalf("collect %v exclude %v: missing %s in %v", tc.collect, tc.exclude, tc.want, v)
+		}
+		if _, ok := v[tc.unwanted]; ok {
+			t.Fatalf("collect %v exclude %v: unexpected %s", tc.collect, tc.exclude, tc.unwanted)
+		}
+	}
+
+	if _, err := exporter.View(ctx, []string{"c"}, nil); err == nil {
+		t.Fatal("expected an error for an unknown collector")
+	}
+	if _, err := exporter.View(ctx, []string{"a"}, []string{"b"}); err == nil {
+		t.Fatal("expected an error for collect[] along with exclude[]")
+	}
+}
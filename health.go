@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// healthy serves /-/healthy: answering at all means the process is alive
+func healthy(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("OK\n"))
+}
+
+// readiness serves /-/ready, checking that the exporter can collect: the eos
+// binary is executable, the MGM answers and the audit log, when the audit
+// collector is enabled, is readable. Replayed outputs need neither the binary
+// nor the MGM, those checks are then skipped.
+type readiness struct {
+	binary string
+	client *eosclient.Client
+	replay bool
+
+	mu        sync.Mutex
+	auditPath string // empty when the audit collector is disabled
+}
+
+// setAuditLogPath sets the audit log checked by the probe, empty to skip the check
+func (rd *readiness) setAuditLogPath(path string) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.auditPath = path
+}
+
+// readinessCheck is one of the checks of /-/ready, skipped when check is nil
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (rd *readiness) checks() []readinessCheck {
+	rd.mu.Lock()
+	auditPath := rd.auditPath
+	rd.mu.Unlock()
+
+	checks := []readinessCheck{
+		{name: "eos_binary"},
+		{name: "mgm"},
+		{name: "audit_log"},
+	}
+	if !rd.replay {
+		checks[0].check = func(context.Context) error { return checkExecutable(rd.binary) }
+		checks[1].check = rd.client.Ping
+	}
+	if auditPath != "" {
+		checks[2].check = func(context.Context) error { return checkReadable(auditPath) }
+	}
+	return checks
+}
+
+func (rd *readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var report strings.Builder
+	status := http.StatusOK
+	for _, c := range rd.checks() {
+		result := "skipped"
+		if c.check != nil {
+			result = "ok"
+			if err := c.check(r.Context()); err != nil {
+				result = err.Error()
+				status = http.StatusServiceUnavailable
+			}
+		}
+		fmt.Fprintf(&report, "%s: %s\n", c.name, result)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(report.String()))
+}
+
+// checkExecutable returns an error unless path is an executable file
+func checkExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
+
+// checkReadable returns an error unless path can be opened for reading
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// failingExecutor fails every eos command
+type failingExecutor struct{}
+
+func (failingExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	return "", "connection refused", errors.New("exit status 255")
+}
+
+func TestReadiness(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "eos")
+	auditLog := filepath.Join(dir, "audit.zstd")
+	for path, mode := range map[string]os.FileMode{binary: 0o755, auditLog: 0o644} {
+		if err := os.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newClient := func(executor eosclient.Executor) *eosclient.Client {
+		client, err := eosclient.New(&eosclient.Options{Timeout: 1, Executor: executor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	for _, tc := range []struct {
+		name   string
+		ready  *readiness
+		audit  string
+		status int
+		body   []string
+	}{
+		{
+			name:   "ready",
+			ready:  &readiness{binary: binary, client: newClient(nopExecutor{})},
+			status: http.StatusOK,
+			body:   []string{"eos_binary: ok", "mgm: ok", "audit_log: skipped"},
+		},
+		{
+			name:   "ready with the audit log",
+			ready:  &readiness{binary: binary, client: newClient(nopExecutor{})},
+			audit:  auditLog,
+			status: http.StatusOK,
+			body:   []string{"audit_log: ok"},
+		},
+		{
+			name:   "mgm down",
+			ready:  &readiness{binary: binary, client: newClient(failingExecutor{})},
+			status: http.StatusServiceUnavailable,
+			body:   []string{"eos_binary: ok", "mgm: eos whoami", "connection refused"},
+		},
+		{
+			name:   "missing binary",
+			ready:  &readiness{binary: filepath.Join(dir, "nope"), client: newClient(nopExecutor{})},
+			status: http.StatusServiceUnavailable,
+			body:   []string{"eos_binary: stat", "mgm: ok"},
+		},
+		{
+			name:   "unreadable audit log",
+			ready:  &readiness{binary: binary, client: newClient(nopExecutor{})},
+			audit:  filepath.Join(dir, "missing.zstd"),
+			status: http.StatusServiceUnavailable,
+			body:   []string{"audit_log: open"},
+		},
+		{
+			name:   "replay",
+			ready:  &readiness{binary: filepath.Join(dir, "nope"), client: newClient(failingExecutor{}), replay: true},
+			audit:  auditLog,
+			status: http.StatusOK,
+			body:   []string{"eos_binary: skipped", "mgm: skipped", "audit_log: ok"},
+		},
+	} {
+		tc.ready.setAuditLogPath(tc.audit)
+		rec := httptest.NewRecorder()
+		tc.ready.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d:\n%s", tc.name, tc.status, rec.Code, rec.Body.String())
+		}
+		for _, want := range tc.body {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("%s: expected %q in:\n%s", tc.name, want, rec.Body.String())
+			}
+		}
+	}
+}
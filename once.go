@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// writeOnce runs the collectors of the specs a single time and writes their metrics
+// in the text exposition format to path, e.g. for the textfile collector of the
+// node_exporter. A collector enabled on several endpoints runs once. The file is
+// replaced atomically, so that a reader never sees it half written.
+//
+// The audit collector is left out: its counters are filled by a background watcher
+// of the rotated audit logs, so a single run would only write empty counters.
+func writeOnce(path string, exporter *EOSExporter, specs map[string][]collectorSpec) error {
+	seen := make(map[string]bool)
+	var unique []collectorSpec
+	for _, c := range availableCollectors {
+		for _, endpointSpecs := range specs {
+			for _, spec := range endpointSpecs {
+				if spec.name != c.name || seen[spec.name] {
+					continue
+				}
+				seen[spec.name] = true
+				if spec.name == "audit" {
+					log.Println("The audit collector does not run with --once, leaving it out")
+					continue
+				}
+				unique = append(unique, spec)
+			}
+		}
+	}
+	if len(unique) == 0 {
+		return fmt.Errorf("no collector enabled")
+	}
+	exporter.apply(unique)
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(exporter); err != nil {
+		return err
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(tmp, family); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	// Readable by the node_exporter, which usually runs as another user
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cern-eos/eos_exporter/collector"
+)
+
+func TestWriteOnceLeavesAuditOut(t *testing.T) {
+	exporter := newEOSExporter("test", collector.NewSnapshot(nopExecutor{}, "test"), exporterOpts{Workers: 1})
+	t.Cleanup(exporter.Stop)
+
+	path := filepath.Join(t.TempDir(), "eos.prom")
+	err := writeOnce(path, exporter, map[string][]collectorSpec{
+		endpointStandard: fakeSpecs(t, collectorSettings{}, map[string]*fakeCollector{"fs": newFakeCollector("fs"), "audit": newFakeCollector("audit")}),
+		"other":          fakeSpecs(t, collectorSettings{}, map[string]*fakeCollector{"fs": newFakeCollector("fs")}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "fake_fs 0") || strings.Contains(string(content), "fake_audit") {
+		t.Fatalf("unexpected metrics written:\n%s", content)
+	}
+
+	// Nothing left to run
+	err = writeOnce(path, exporter, map[string][]collectorSpec{
+		endpointStandard: fakeSpecs(t, collectorSettings{}, map[string]*fakeCollector{"audit": newFakeCollector("audit")}),
+	})
+	if err == nil {
+		t.Fatal("expected an error without any collector to run")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// defaultModule is the module probed when the module parameter is left out. Unless
+// the configuration defines it, it runs all the collectors but the audit one.
+const defaultModule = "default"
+
+// clientFactory creates the client of an instance, reporting its commands in metrics,
+// or in the metrics of the local instance when nil
+type clientFactory func(url string, executor eosclient.Executor, metrics *eosclient.CommandMetrics) (*eosclient.Client, error)
+
+// prober serves /probe?target=<instance>&module=<module>, running the collectors
+// of the module against one of the instances of the configuration, like the
+// blackbox_exporter. The target is the name or the MGM URL of the instance, and
+// the metrics are labelled with its name.
+type prober struct {
+	guards    *guards
+	newClient clientFactory
+	base      collector.CollectorOpts
+
+	mu        sync.Mutex
+	cfg       *Config
+	targets   map[string]*probeTarget // by MGM URL
+	exporters map[string]*EOSExporter // by instance and module, created on the first probe
+}
+
+// probeTarget holds what the probes of an instance share, whatever their module
+type probeTarget struct {
+	guard    *eosclient.GuardedExecutor // protects the MGM of the instance, shared with the local endpoints when it is the local one
+	client   *eosclient.Client
+	registry *prometheus.Registry // metrics served along with the ones of the collectors
+}
+
+func newProber(guards *guards, newClient clientFactory, base collector.CollectorOpts, cfg *Config) *prober {
+	return &prober{
+		guards:    guards,
+		newClient: newClient,
+		base:      base,
+		cfg:       cfg,
+		targets:   make(map[string]*probeTarget),
+		exporters: make(map[string]*EOSExporter),
+	}
+}
+
+// reload replaces the configuration, dropping the collectors of the previous one
+func (p *prober) reload(cfg *Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, exporter := range p.exporters {
+		exporter.apply(nil)
+	}
+	p.cfg = cfg
+	p.exporters = make(map[string]*EOSExporter)
+}
+
+// exporter returns the exporter running the module against the target and the
+// registry of the other metrics of the target
+func (p *prober) exporter(target, moduleName string) (*EOSExporter, prometheus.Gatherer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var instance *InstanceConfig
+	for i := range p.cfg.Instances {
+		if p.cfg.Instances[i].Name == target || p.cfg.Instances[i].URL == target {
+			instance = &p.cfg.Instances[i]
+			break
+		}
+	}
+	if instance == nil {
+		return nil, nil, fmt.Errorf("unknown target %q", target)
+	}
+
+	module, ok := p.cfg.Modules[moduleName]
+	if !ok {
+		if moduleName != defaultModule {
+			return nil, nil, fmt.Errorf("unknown module %q", moduleName)
+		}
+		for _, c := range availableCollectors {
+			if c.name != "audit" {
+				module.Collectors = append(module.Collectors, c.name)
+			}
+		}
+	}
+
+	pt, ok := p.targets[instance.URL]
+	if !ok {
+		// The commands run against the target are reported under its name, apart from the local ones
+		metrics := eosclient.NewCommandMetrics()
+		pt = &probeTarget{guard: p.guards.get(instance.URL), registry: prometheus.NewRegistry()}
+		var err error
+		if pt.client, err = p.newClient(instance.URL, pt.guard, metrics); err != nil {
+			return nil, nil, err
+		}
+		prometheus.WrapRegistererWith(prometheus.Labels{"cluster": instance.Name}, pt.registry).MustRegister(pt.guard, metrics)
+		p.targets[instance.URL] = pt
+	}
+
+	key := instance.Name + "\x00" + moduleName
+	if exporter, ok := p.exporters[key]; ok {
+		return exporter, pt.registry, nil
+	}
+
+	opts := p.cfg.collectorOpts(p.base)
+	opts.Cluster = instance.Name
+	opts.Client = pt.client
+
+	var specs []collectorSpec
+	for _, c := range availableCollectors {
+		for _, name := range module.Collectors {
+			if c.name != name {
+				continue
+			}
+			settings := p.cfg.settings(name)
+			if module.Timeout > 0 {
+				settings.Timeout = module.Timeout
+			}
+			specs = append(specs, collectorSpec{name: name, creator: c.creator, opts: opts, settings: settings})
+		}
+	}
+
+	// Probes always run the collectors, as scrapes do without background polling
+	exporter := newEOSExporter(instance.Name, collector.NewSnapshot(pt.guard, instance.Name), exporterOpts{Workers: cmdOptions.MaxConcurrency, Coalesce: cmdOptions.CoalesceWindow})
+	exporter.apply(specs)
+	p.exporters[key] = exporter
+	return exporter, pt.registry, nil
+}
+
+func (p *prober) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	module := query.Get("module")
+	if module == "" {
+		module = defaultModule
+	}
+
+	exporter, gatherer, err := p.exporter(query.Get("target"), module)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	serveView(w, r, exporter, gatherer)
+}
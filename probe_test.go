@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/cern-eos/eos_exporter/collector"
+	"github.com/cern-eos/eos_exporter/eosclient"
+)
+
+// whoExecutor answers every eos command with an eos who -a -m output and
+// remembers the command lines it ran
+type whoExecutor struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (e *whoExecutor) Execute(ctx context.Context, args ...string) (string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.runs = append(e.runs, strings.Join(args, " "))
+	return "client=jdoe@lxplus1.cern.ch uid=jdoe auth=krb5 idle=1 gateway=\"\" app=fuse\n", "", nil
+}
+
+// commandSeries returns the number of eos_command_duration_seconds series of command
+func commandSeries(t *testing.T, c prometheus.Collector, command string) int {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, family := range families {
+		if family.GetName() != "eos_command_duration_seconds" {
+			continue
+		}
+		for _, m := range family.Metric {
+			if labelValue(m, "command") == command {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, label := range m.Label {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+func newTestProber(t *testing.T, executor eosclient.Executor) *prober {
+	t.Helper()
+	cfg, err := parseConfig(t, `
+modules:
+  users:
+    collectors: [who]
+instances:
+  - name: remote
+    url: root://eos-remote.cern.ch
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func(url string, executor eosclient.Executor, metrics *eosclient.CommandMetrics) (*eosclient.Client, error) {
+		return eosclient.New(&eosclient.Options{URL: url, Timeout: 5, Executor: executor, Metrics: metrics})
+	}
+	p := newProber(newGuards(executor), newClient, collector.CollectorOpts{}, cfg)
+	t.Cleanup(func() { p.reload(&Config{}) })
+	return p
+}
+
+func TestProbe(t *testing.T) {
+	executor := &whoExecutor{}
+	p := newTestProber(t, executor)
+	localWho := commandSeries(t, eosclient.Metrics()[0], "who")
+
+	for _, tc := range []struct {
+		url    string
+		status int
+		body   []string
+	}{
+		{"/probe?target=remote&module=users", http.StatusOK, []string{
+			`eos_who{app="fuse",auth="krb5",cluster="remote",gateway="",uid="jdoe"} 1`,
+			`eos_command_duration_seconds_count{cluster="remote",command="who"} 1`,
+			`eos_scrape_collector_success{cluster="remote",collector="who"} 1`,
+		}},
+		// By MGM URL, the metrics are still labelled with the name of the instance
+		{"/probe?target=root://eos-remote.cern.ch&module=users", http.StatusOK, []string{
+			`eos_who{app="fuse",auth="krb5",cluster="remote",gateway="",uid="jdoe"} 1`,
+			`eos_command_duration_seconds_count{cluster="remote",command="who"} 2`,
+		}},
+		{"/probe?target=root://eos-other.cern.ch&module=users", http.StatusBadRequest, []string{`unknown target "root://eos-other.cern.ch"`}},
+		{"/probe?module=users", http.StatusBadRequest, []string{`unknown target ""`}},
+		{"/probe?target=remote&module=nope", http.StatusBadRequest, []string{`unknown module "nope"`}},
+	} {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d:\n%s", tc.url, tc.status, rec.Code, rec.Body.String())
+			continue
+		}
+		for _, want := range tc.body {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("%s: expected %q in:\n%s", tc.url, want, rec.Body.String())
+			}
+		}
+	}
+
+	// The commands ran against the target, and are left out of the command metrics of /metrics
+	for _, run := range executor.runs {
+		if !strings.Contains(run, "root://eos-remote.cern.ch who -a -m") {
+			t.Errorf("unexpected command line %q", run)
+		}
+	}
+	if got := commandSeries(t, eosclient.Metrics()[0], "who"); got != localWho {
+		t.Errorf("the probe commands were reported in the command metrics of /metrics")
+	}
+}
+
+func TestProbeDefaultModule(t *testing.T) {
+	p := newTestProber(t, nopExecutor{})
+
+	exporter, _, err := p.exporter("remote", defaultModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, mc := range exporter.collectors {
+		names = append(names, mc.name)
+	}
+	if len(names) != len(availableCollectors)-1 {
+		t.Fatalf("expected all the collectors but one, got %v", names)
+	}
+	for _, name := range names {
+		if name == "audit" {
+			t.Fatal("the default module runs the audit collector")
+		}
+	}
+}
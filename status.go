@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusPage serves the inventory of the collectors along with the last run of each
+// of them, as an HTML page on "/" and as JSON on /api/status, so that the state
+// of the exporter can be checked without reading its logs
+type statusPage struct {
+	endpoints []*endpoint
+}
+
+type buildStatus struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// collectorRunStatus is the last run of a collector on one of its endpoints
+type collectorRunStatus struct {
+	Endpoint        string     `json:"endpoint"`
+	ListenAddress   string     `json:"listen_address"`
+	Path            string     `json:"path"`
+	LastRun         *time.Time `json:"last_run,omitempty"`
+	LastSuccess     *time.Time `json:"last_success,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	LastError       string     `json:"last_error,omitempty"`
+	Series          int        `json:"series"`
+}
+
+type collectorStatus struct {
+	Name      string               `json:"name"`
+	Enabled   bool                 `json:"enabled"`
+	Endpoints []collectorRunStatus `json:"endpoints"`
+}
+
+type exporterStatus struct {
+	Instance   string            `json:"instance"`
+	Build      buildStatus       `json:"build"`
+	Collectors []collectorStatus `json:"collectors"`
+}
+
+// timePtr returns nil for the zero time, so that it is left out of the JSON
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// status lists every available collector, in the order of availableCollectors.
+// A collector is enabled when at least one endpoint runs it.
+func (p *statusPage) status() exporterStatus {
+	reports := make([]map[string]collectorReport, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		reports[i] = ep.exporter.reports()
+	}
+
+	st := exporterStatus{
+		Instance: cmdOptions.EOSInstance,
+		Build: buildStatus{
+			Version:   strings.TrimSpace(version),
+			GitCommit: strings.TrimSpace(gitCommit),
+			BuildDate: strings.TrimSpace(buildDate),
+			GoVersion: strings.TrimSpace(goVersion),
+		},
+	}
+	for _, c := range availableCollectors {
+		cs := collectorStatus{Name: c.name, Endpoints: []collectorRunStatus{}}
+		for i, ep := range p.endpoints {
+			report, ok := reports[i][c.name]
+			if !ok {
+				continue
+			}
+			run := collectorRunStatus{
+				Endpoint:        ep.Name,
+				ListenAddress:   ep.ListenAddress,
+				Path:            ep.Path,
+				LastRun:         timePtr(report.LastRun),
+				LastSuccess:     timePtr(report.LastSuccess),
+				DurationSeconds: report.Duration.Seconds(),
+				Series:          report.Series,
+			}
+			if report.Err != nil {
+				run.LastError = report.Err.Error()
+			}
+			cs.Endpoints = append(cs.Endpoints, run)
+		}
+		cs.Enabled = len(cs.Endpoints) > 0
+		st.Collectors = append(st.Collectors, cs)
+	}
+	return st
+}
+
+// ServeHTTP serves the status as JSON
+func (p *statusPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(p.status())
+}
+
+var statusTemplate = template.Must(template.New("status").Parse(`<html>
+<head><title>EOS Exporter</title></head>
+<body>
+<h1>EOS Exporter</h1>
+{{range .Links}}<p><a href="{{.Path}}">Metrics ({{.Name}})</a></p>
+{{end}}<p><a href="/api/status">Status (JSON)</a></p>
+<h2>Build</h2>
+<p>Instance {{.Status.Instance}}, version {{.Status.Build.Version}}, commit {{.Status.Build.GitCommit}},
+built {{.Status.Build.BuildDate}} with {{.Status.Build.GoVersion}}</p>
+<h2>Collectors</h2>
+<table border="1" cellpadding="4">
+<tr><th>Collector</th><th>Enabled</th><th>Endpoint</th><th>Last run</th><th>Duration</th><th>Series</th><th>Last error</th></tr>
+{{range .Status.Collectors}}{{$name := .Name}}{{if .Enabled}}{{range .Endpoints}}<tr><td>{{$name}}</td><td>yes</td><td>{{.Endpoint}} ({{.ListenAddress}}{{.Path}})</td>
+<td>{{if .LastRun}}{{.LastRun.Format "2006-01-02 15:04:05 MST"}}{{else}}never{{end}}</td><td>{{printf "%.3fs" .DurationSeconds}}</td><td>{{.Series}}</td><td>{{.LastError}}</td></tr>
+{{end}}{{else}}<tr><td>{{.Name}}</td><td>no</td><td></td><td></td><td></td><td></td><td></td></tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+// page returns the HTML status page of a listener, linking the metrics of its endpoints
+func (p *statusPage) page(local []*endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := statusTemplate.Execute(w, struct {
+			Links  []*endpoint
+			Status exporterStatus
+		}{local, p.status()})
+		if err != nil {
+			log.Printf("Failed to render the status page: %v", err)
+		}
+	})
+}
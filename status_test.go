@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	fs, node := newFakeCollector("fs"), newFakeCollector("node")
+	fs.set(0, errors.New("eos fs ls -m: exit status 255"))
+	node.set(1, nil)
+	exporter := newTestExporter(t, exporterOpts{Workers: 2}, collectorSettings{}, map[string]*fakeCollector{"fs": fs, "node": node})
+	exporter.join(context.Background(), exporter.collectors)
+
+	ep := &endpoint{EndpointConfig: EndpointConfig{Name: endpointStandard, ListenAddress: ":9986", Path: "/metrics"}, exporter: exporter}
+	page := &statusPage{endpoints: []*endpoint{ep}}
+
+	rec := httptest.NewRecorder()
+	page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	var st exporterStatus
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Collectors) != len(availableCollectors) {
+		t.Fatalf("expected every available collector, got %d", len(st.Collectors))
+	}
+
+	byName := make(map[string]collectorStatus)
+	for _, cs := range st.Collectors {
+		byName[cs.Name] = cs
+	}
+	if space := byName["space"]; space.Enabled || len(space.Endpoints) != 0 {
+		t.Fatalf("expected space to be disabled, got %+v", space)
+	}
+	for name, want := range map[string]struct {
+		series  int
+		lastErr string
+	}{
+		"fs":   {0, "eos fs ls -m: exit status 255"},
+		"node": {1, ""},
+	} {
+		cs := byName[name]
+		if !cs.Enabled || len(cs.Endpoints) != 1 {
+			t.Fatalf("%s: expected to be enabled on one endpoint, got %+v", name, cs)
+		}
+		run := cs.Endpoints[0]
+		if run.Endpoint != endpointStandard || run.ListenAddress != ":9986" || run.Path != "/metrics" {
+			t.Errorf("%s: unexpected endpoint %+v", name, run)
+		}
+		if run.LastRun == nil || run.Series != want.series || run.LastError != want.lastErr {
+			t.Errorf("%s: unexpected last run %+v", name, run)
+		}
+		if (run.LastSuccess != nil) != (want.lastErr == "") {
+			t.Errorf("%s: unexpected last success %v", name, run.LastSuccess)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	page.page([]*endpoint{ep}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{`<a href="/metrics">`, "<td>fs</td><td>yes</td>", "exit status 255", "<td>space</td><td>no</td>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the status page:\n%s", want, body)
+		}
+	}
+}